@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Command.AddCommand(loginCommand)
+	Command.AddCommand(logoutCommand)
+	Command.AddCommand(statusCommand)
+}
+
+var Command = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage the cached patreon cookie",
+	Long:  "Log in with a patreon cookie, log out or check whether the cached cookie is still valid. The cookie is cached in the patreon-crawler config directory, optionally encrypted (see --credential-store).",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return cmd.Help()
+	},
+}
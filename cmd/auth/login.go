@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/MatthiasHarzer/patreon-crawler/cmd/cmdutils"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var argCookie string
+var argCookiesFile string
+
+func init() {
+	loginCommand.Flags().StringVarP(&argCookie, "cookie", "c", argCookie, "The cookie to log in with. If neither this nor --cookies-file is set, the cookie is read from stdin")
+	loginCommand.Flags().StringVarP(&argCookiesFile, "cookies-file", "", argCookiesFile, "A Netscape cookies.txt or JSON cookie export to read the patreon.com cookies from")
+}
+
+var loginCommand = &cobra.Command{
+	Use:   "login",
+	Short: "Verify a patreon cookie and cache it for later commands",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		apiClient, err := cmdutils.Login(argCookie, argCookiesFile)
+		if err != nil {
+			return fmt.Errorf("failed to log in: %w", err)
+		}
+
+		user, err := patreon.GetCurrentUser(apiClient)
+		if err != nil {
+			return fmt.Errorf("failed to get current user: %w", err)
+		}
+
+		fmt.Printf("Logged in as %s.\n", color.GreenString(user.Name))
+		return nil
+	},
+}
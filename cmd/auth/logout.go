@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/MatthiasHarzer/patreon-crawler/cmd/cmdutils"
+	"github.com/spf13/cobra"
+)
+
+var logoutCommand = &cobra.Command{
+	Use:   "logout",
+	Short: "Delete the cached patreon cookie",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		loggedOut, err := cmdutils.Logout()
+		if err != nil {
+			return fmt.Errorf("failed to delete cached cookie: %w", err)
+		}
+
+		if !loggedOut {
+			fmt.Println("Not logged in.")
+			return nil
+		}
+		fmt.Println("Logged out.")
+		return nil
+	},
+}
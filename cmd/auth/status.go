@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/MatthiasHarzer/patreon-crawler/cmd/cmdutils"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var argStatusCookie string
+var argStatusCookiesFile string
+
+func init() {
+	statusCommand.Flags().StringVarP(&argStatusCookie, "cookie", "c", argStatusCookie, "The cookie to check instead of the cached one")
+	statusCommand.Flags().StringVarP(&argStatusCookiesFile, "cookies-file", "", argStatusCookiesFile, "A Netscape cookies.txt or JSON cookie export to check instead of the cached cookie")
+}
+
+var statusCommand = &cobra.Command{
+	Use:   "status",
+	Short: "Check whether the cached patreon cookie is valid",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		cookie, err := cmdutils.CookieFromFlags(argStatusCookie, argStatusCookiesFile)
+		if err != nil {
+			return err
+		}
+		if cookie == "" {
+			cookie, err = cmdutils.CachedCookie()
+			if err != nil {
+				return fmt.Errorf("failed to read cached cookie: %w", err)
+			}
+		}
+		if cookie == "" {
+			fmt.Printf("%s Run \"patreon-crawler auth login\" to log in.\n", color.YellowString("Not logged in."))
+			return nil
+		}
+
+		apiClient := api.NewClient(cookie, cmdutils.Proxy())
+		authenticated, err := apiClient.IsAuthenticated()
+		if err != nil {
+			return fmt.Errorf("failed to check authentication: %w", err)
+		}
+		if !authenticated {
+			return fmt.Errorf("the cookie is invalid or expired. Run \"patreon-crawler auth login\" to log in again")
+		}
+
+		user, err := patreon.GetCurrentUser(apiClient)
+		if err != nil {
+			return fmt.Errorf("failed to get current user: %w", err)
+		}
+
+		fmt.Printf("Logged in as %s.\n", color.GreenString(user.Name))
+		fmt.Printf("Email:       %s\n", user.Email)
+		fmt.Printf("Memberships: %d\n", user.MembershipCount)
+		return nil
+	},
+}
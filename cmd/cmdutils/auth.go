@@ -0,0 +1,214 @@
+package cmdutils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/MatthiasHarzer/patreon-crawler/credentials"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+	"github.com/MatthiasHarzer/patreon-crawler/util/cookieutils"
+	"golang.org/x/term"
+)
+
+func readCookie(reader *bufio.Reader) (string, error) {
+	cookie, err := reader.ReadString('\n')
+	cookie = strings.TrimSpace(cookie)
+	if errors.Is(err, io.EOF) && cookie != "" {
+		return cookie, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return cookie, nil
+}
+
+func getAPIClientFromStdIn() (api.Client, string, error) {
+	var apiClient api.Client
+	var cookie string
+	var err error
+	authenticated := false
+
+	reader := bufio.NewReader(os.Stdin)
+	for !authenticated {
+		fmt.Println("Please enter your cookie from the patreon website: ")
+		cookie, err = readCookie(reader)
+		if errors.Is(err, io.EOF) {
+			return nil, "", fmt.Errorf("no cookie entered: %w", api.ErrUnauthenticated)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		apiClient = api.NewClient(cookie, Proxy())
+		authenticated, err = apiClient.IsAuthenticated()
+		if err != nil {
+			return nil, "", err
+		}
+		if !authenticated {
+			fmt.Println("Unable to authenticate with the provided cookie. Please try again.")
+		}
+	}
+
+	return apiClient, cookie, nil
+}
+
+const cookieDomain = "patreon.com"
+
+func CookieFromFlags(cookie, cookiesFile string) (string, error) {
+	if cookiesFile == "" {
+		return cookie, nil
+	}
+	if cookie != "" {
+		return "", fmt.Errorf("--cookie and --cookies-file cannot be used together")
+	}
+	return cookieutils.ReadHeader(cookiesFile, cookieDomain)
+}
+
+func CachedCookie() (string, error) {
+	cookie, err := loadCookie(activeProfile)
+	if errors.Is(err, credentials.ErrNotFound) {
+		return "", nil
+	}
+	return cookie, err
+}
+
+func Login(cookie, cookiesFile string) (api.Client, error) {
+	cookie, err := CookieFromFlags(cookie, cookiesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiClient api.Client
+	if cookie != "" {
+		apiClient = api.NewClient(cookie, Proxy())
+		authenticated, err := apiClient.IsAuthenticated()
+		if err != nil {
+			return nil, err
+		}
+		if !authenticated {
+			return nil, fmt.Errorf("failed to authenticate with the provided cookie")
+		}
+	} else {
+		apiClient, cookie, err = getAPIClientFromStdIn()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = saveCookie(activeProfile, cookie)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save cookie: %w", err)
+	}
+	return apiClient, nil
+}
+
+// Logout also deletes a cookie cached by older versions.
+func Logout() (bool, error) {
+	err := deleteCookie(activeProfile)
+	if !errors.Is(err, credentials.ErrNotFound) {
+		return err == nil, err
+	}
+	if activeProfile != "" {
+		return false, nil
+	}
+
+	legacyFile, err := legacyCookieFile()
+	if err != nil {
+		return false, err
+	}
+	err = os.Remove(legacyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func GetAPIClient(cookie, cookiesFile string) (api.Client, error) {
+	cookie, err := CookieFromFlags(cookie, cookiesFile)
+	if err != nil {
+		return nil, err
+	}
+	if cookie != "" {
+		apiClient := api.NewClient(cookie, Proxy())
+		authenticated, err := apiClient.IsAuthenticated()
+		if err != nil {
+			return nil, err
+		}
+		if authenticated {
+			return apiClient, nil
+		}
+		return nil, fmt.Errorf("the cookie provided via --cookie or --cookies-file was rejected: %w", api.ErrUnauthenticated)
+	}
+
+	cookie, err = loadCookie(activeProfile)
+	if err != nil && !errors.Is(err, credentials.ErrNotFound) {
+		return nil, fmt.Errorf("failed to load cached cookie: %w", err)
+	}
+	if err == nil {
+		apiClient := api.NewClient(cookie, Proxy())
+		authenticated, err := apiClient.IsAuthenticated()
+		if err != nil {
+			return nil, err
+		}
+		if authenticated {
+			return apiClient, nil
+		}
+	}
+
+	apiClient, cookie, err := getAPIClientFromStdIn()
+	if err != nil {
+		return nil, err
+	}
+
+	err = saveCookie(activeProfile, cookie)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiClient, nil
+}
+
+// GetProfileAPIClient never prompts, unlike GetAPIClient.
+func GetProfileAPIClient(profile string) (api.Client, error) {
+	cookie, err := loadCookie(profile)
+	if errors.Is(err, credentials.ErrNotFound) {
+		return nil, fmt.Errorf("no cookie cached for profile %s. Run \"patreon-crawler --profile %s auth login\" to log in", profile, profile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cached cookie of profile %s: %w", profile, err)
+	}
+
+	apiClient := api.NewClient(cookie, Proxy())
+	authenticated, err := apiClient.IsAuthenticated()
+	if err != nil {
+		return nil, err
+	}
+	if !authenticated {
+		return nil, fmt.Errorf("profile %s: %w. Run \"patreon-crawler --profile %s auth login\" to log in again", profile, api.ErrUnauthenticated, profile)
+	}
+	return apiClient, nil
+}
+
+// Reauthenticate returns an error wrapping api.ErrUnauthenticated without an
+// interactive terminal.
+func Reauthenticate(profile string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("%w. Run \"patreon-crawler auth login\" to log in again", api.ErrUnauthenticated)
+	}
+
+	_, cookie, err := getAPIClientFromStdIn()
+	if err != nil {
+		return "", err
+	}
+	err = saveCookie(profile, cookie)
+	if err != nil {
+		return "", fmt.Errorf("failed to save cookie: %w", err)
+	}
+	return cookie, nil
+}
@@ -0,0 +1,162 @@
+package cmdutils
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/MatthiasHarzer/patreon-crawler/config"
+	"github.com/MatthiasHarzer/patreon-crawler/credentials"
+	"github.com/MatthiasHarzer/patreon-crawler/util/netutils"
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+const PassphraseEnv = "PATREON_CRAWLER_PASSPHRASE"
+
+var credentialBackend = credentials.BackendPlain
+var credentialStores = make(map[string]credentials.Store)
+var activeProfile string
+var proxyURL *url.URL
+
+func Setup(profile string, backend credentials.Backend, proxy string) error {
+	if profile != "" {
+		err := config.ValidateProfileName(profile)
+		if err != nil {
+			return err
+		}
+	}
+	err := credentials.ValidateBackend(backend)
+	if err != nil {
+		return err
+	}
+	parsedProxy, err := netutils.ParseProxyURL(proxy)
+	if err != nil {
+		return err
+	}
+	activeProfile = profile
+	credentialBackend = backend
+	proxyURL = parsedProxy
+	return nil
+}
+
+// ActiveProfile returns the empty string for the default profile.
+func ActiveProfile() string {
+	return activeProfile
+}
+
+func Proxy() *url.URL {
+	return proxyURL
+}
+
+func getCredentialStore(profile string) (credentials.Store, error) {
+	if store, ok := credentialStores[profile]; ok {
+		return store, nil
+	}
+	dir, err := config.ProfileDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	store, err := credentials.NewStore(credentialBackend, dir, promptPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	credentialStores[profile] = store
+	return store, nil
+}
+
+func readPassword(prompt string) ([]byte, error) {
+	fmt.Print(prompt)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	return passphrase, err
+}
+
+func promptPassphrase(confirm bool) ([]byte, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("the encrypted credential store requires a passphrase. Set %s or run in a terminal", PassphraseEnv)
+	}
+
+	passphrase, err := readPassword("Passphrase for the cookie store: ")
+	if err != nil {
+		return nil, err
+	}
+	if !confirm {
+		return passphrase, nil
+	}
+	repeated, err := readPassword("Repeat the passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if string(passphrase) != string(repeated) {
+		return nil, fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+func legacyCookieFile() (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(usr.HomeDir, ".credentials", "patreon.cookie"), nil
+}
+
+func migrateLegacyCookie(store credentials.Store) (string, error) {
+	legacyFile, err := legacyCookieFile()
+	if err != nil {
+		return "", err
+	}
+	cookie, err := os.ReadFile(legacyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", credentials.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = store.Save(string(cookie))
+	if err != nil {
+		return "", fmt.Errorf("failed to migrate cookie from %s: %w", legacyFile, err)
+	}
+	err = os.Remove(legacyFile)
+	if err != nil {
+		return "", err
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Moved the cached cookie from %s to the credential store.\n", color.YellowString(legacyFile))
+	return string(cookie), nil
+}
+
+func loadCookie(profile string) (string, error) {
+	store, err := getCredentialStore(profile)
+	if err != nil {
+		return "", err
+	}
+	cookie, err := store.Load()
+	if errors.Is(err, credentials.ErrNotFound) && profile == "" {
+		return migrateLegacyCookie(store)
+	}
+	return cookie, err
+}
+
+func saveCookie(profile string, cookie string) error {
+	store, err := getCredentialStore(profile)
+	if err != nil {
+		return err
+	}
+	return store.Save(cookie)
+}
+
+func deleteCookie(profile string) error {
+	store, err := getCredentialStore(profile)
+	if err != nil {
+		return err
+	}
+	return store.Delete()
+}
@@ -0,0 +1,35 @@
+package cmdutils
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+)
+
+func ErrorHint(err error) string {
+	if errors.Is(err, api.ErrCampaignNotFound) {
+		return "Creator not found. Check the creator name, URL or campaign ID."
+	}
+
+	var apiError *api.Error
+	if !errors.As(err, &apiError) {
+		if errors.Is(err, api.ErrUnauthenticated) {
+			return "Not logged in. Run \"patreon-crawler auth login\" to log in."
+		}
+		return ""
+	}
+	switch {
+	case apiError.StatusCode == http.StatusUnauthorized:
+		return "The cookie is invalid or expired. Run \"patreon-crawler auth login\" to log in again."
+	case apiError.StatusCode == http.StatusForbidden:
+		return "Access denied. Either the cookie expired or you are not a patron of the creator. Run \"patreon-crawler auth status\" to check the cookie."
+	case apiError.StatusCode == http.StatusNotFound:
+		return "Not found. Check the creator ID or post ID."
+	case apiError.StatusCode == http.StatusTooManyRequests:
+		return "Rate limited by patreon. Wait a few minutes or lower --concurrency."
+	case apiError.StatusCode >= http.StatusInternalServerError:
+		return "Patreon is having problems. Try again later."
+	}
+	return ""
+}
@@ -0,0 +1,16 @@
+package cmdutils
+
+import (
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+)
+
+// MembershipCampaigns returns the campaigns of all memberships of the current
+// user matching the include and exclude patterns.
+func MembershipCampaigns(apiClient api.Client, include, exclude []string) ([]patreon.Campaign, error) {
+	campaigns, err := patreon.GetCampaigns(apiClient)
+	if err != nil {
+		return nil, err
+	}
+	return patreon.FilterCampaigns(campaigns, include, exclude)
+}
@@ -0,0 +1,48 @@
+package cmdutils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MatthiasHarzer/patreon-crawler/crawling"
+	"github.com/MatthiasHarzer/patreon-crawler/util/byteutils"
+)
+
+func IsValidMediaSelection(selection crawling.MediaSelection) bool {
+	switch selection {
+	case crawling.MediaSelectionImages, crawling.MediaSelectionAttachments, crawling.MediaSelectionAll:
+		return true
+	default:
+		return false
+	}
+}
+
+func IsValidGroupingStrategy(strategy crawling.GroupingStrategy) bool {
+	switch strategy {
+	case crawling.GroupingStrategyNone, crawling.GroupingStrategyByPost:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseRateLimit returns 0 for an empty value.
+func ParseRateLimit(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return byteutils.ParseRate(value)
+}
+
+func PromptDownloadDir() (string, error) {
+	fmt.Println("Please enter the download directory: ")
+	reader := bufio.NewReader(os.Stdin)
+	downloadDir, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read download directory: %w", err)
+	}
+	downloadDir = strings.TrimSpace(downloadDir)
+	return downloadDir, nil
+}
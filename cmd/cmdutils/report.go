@@ -0,0 +1,28 @@
+package cmdutils
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/download"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/fatih/color"
+)
+
+// NewReportPrinter is safe to call from multiple download workers.
+func NewReportPrinter(prefix string) func(post patreon.Post, reportItem download.ReportItem) {
+	printMutex := sync.Mutex{}
+	return func(post patreon.Post, reportItem download.ReportItem) {
+		printMutex.Lock()
+		defer printMutex.Unlock()
+		fmt.Print(prefix)
+		switch item := reportItem.(type) {
+		case *download.ReportErrorItem:
+			fmt.Printf("[%s] %s from post \"%s\": %s\n", color.RedString("error"), item.Media.ID, color.RedString(post.Title), item.Err)
+		case *download.ReportSkippedItem:
+			fmt.Printf("[%s] %s from post \"%s\" (%s)\n", color.YellowString("skipped"), item.Media.ID, color.YellowString(post.Title), color.RGB(100, 100, 100).Sprint(item.Reason))
+		case *download.ReportSuccessItem:
+			fmt.Printf("[%s] %s from post \"%s\"\n", color.GreenString("downloaded"), item.Media.ID, color.GreenString(post.Title))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package crawl
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/crawling"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+	"github.com/fatih/color"
+)
+
+// authCheckInterval makes a burst of denied requests result in a single check.
+const authCheckInterval = time.Minute
+
+type authGuard struct {
+	apiClient      api.Client
+	downloadQueue  *crawling.DownloadQueue
+	downloader     *crawling.Downloader
+	reauthenticate func() error
+	out            creatorOutput
+
+	mutex     sync.Mutex
+	lastCheck time.Time
+	// err is set if the cookie expired and could not be replaced.
+	err error
+}
+
+// check pauses the downloads of all creators, as they share the cookie.
+func (g *authGuard) check() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.err != nil || time.Since(g.lastCheck) < authCheckInterval {
+		return g.err
+	}
+
+	g.downloadQueue.Pause()
+	defer g.downloadQueue.Resume()
+
+	authenticated, err := g.apiClient.IsAuthenticated()
+	g.lastCheck = time.Now()
+	if err != nil {
+		slog.Warn("failed to check authentication after denied media request", "error", err)
+		return nil
+	}
+	if authenticated {
+		slog.Debug("still authenticated after denied media request")
+		return nil
+	}
+
+	g.out.Printf("%s Downloads are paused.\n", color.YellowString("The patreon cookie has expired."))
+	err = g.reauthenticate()
+	if err != nil {
+		g.err = err
+		g.downloader.Stop()
+		return err
+	}
+	g.lastCheck = time.Now()
+	g.out.Println("Resuming downloads.")
+	return nil
+}
+
+// refresh fetches the post again, as media URLs are signed and requested
+// without the cookie.
+func (g *authGuard) refresh(parentPost patreon.Post, media patreon.Media) (patreon.Media, error) {
+	err := g.check()
+	if err != nil {
+		return patreon.Media{}, err
+	}
+	post, _, err := patreon.GetPost(g.apiClient, parentPost.ID)
+	if err != nil {
+		return patreon.Media{}, fmt.Errorf("failed to fetch post %s: %w", parentPost.ID, err)
+	}
+	postMedia := slices.Concat(post.Media, post.Attachments)
+	index := slices.IndexFunc(postMedia, func(m patreon.Media) bool { return m.ID == media.ID })
+	if index < 0 {
+		return patreon.Media{}, fmt.Errorf("media %s was removed from post %s", media.ID, parentPost.ID)
+	}
+	return postMedia[index], nil
+}
@@ -1,233 +1,420 @@
 package crawl
 
 import (
-	"bufio"
 	"fmt"
 	"math"
-	"net/url"
-	"os"
-	"os/user"
-	"path/filepath"
-	"strings"
 
+	"github.com/MatthiasHarzer/patreon-crawler/cmd/cmdutils"
+	"github.com/MatthiasHarzer/patreon-crawler/config"
 	"github.com/MatthiasHarzer/patreon-crawler/crawling"
-	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
-	"github.com/fatih/color"
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/download"
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/filter"
+	"github.com/MatthiasHarzer/patreon-crawler/notification"
+	"github.com/MatthiasHarzer/patreon-crawler/util/byteutils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+var argConfigFile string
 var argCookie string
+var argCookiesFile string
 var argDownloadDir string
 var argDownloadLimit = math.MaxInt
 var argDownloadInaccessibleMedia bool
 var argGroupingStrategy string
 var argConcurrencyLimit = 4
+var argCreatorConcurrency = 1
+var argConcurrencyPerHost int
+var argLimitRate string
+var argLimitRatePerDownload string
+var argLimitRateSchedule []string
 var argMediaSelection = string(crawling.MediaSelectionImages)
+var argSince string
+var argUntil string
+var argTitleMatch string
+var argExcludeTitle string
+var argPostTypes []string
+var argTags []string
+var argMimeTypes []string
+var argMinSize string
+var argMaxSize string
+var argMinWidth int
+var argMinHeight int
+var argExtensions []string
+var argExcludeExtensions []string
+var argMaxPosts int
+var argMaxMedia int
+var argMaxBytes string
+var argAllMemberships bool
+var argIncludeCreators []string
+var argExcludeCreators []string
+var argWebhookURLs []string
+var argDiscordWebhookURLs []string
+var argSlackWebhookURLs []string
+var argOnNewPost string
 
 func init() {
-	Command.Flags().StringVarP(&argCookie, "cookie", "c", argCookie, "The cookie to use for authentication")
-	Command.Flags().StringVarP(&argDownloadDir, "download-dir", "d", argDownloadDir, "The directory to download posts to")
-	Command.Flags().IntVarP(&argDownloadLimit, "download-limit", "l", argDownloadLimit, "The maximum number of posts to download")
-	Command.Flags().BoolVarP(&argDownloadInaccessibleMedia, "download-inaccessible-media", "", argDownloadInaccessibleMedia, "Whether to download inaccessible media")
-	Command.Flags().StringVarP(&argGroupingStrategy, "grouping", "g", argGroupingStrategy, "The grouping strategy to use. Must be one of: none, by-post")
-	Command.Flags().IntVarP(&argConcurrencyLimit, "concurrency", "", argConcurrencyLimit, "The number of concurrent downloads")
-	Command.Flags().StringVarP(&argMediaSelection, "media", "m", argMediaSelection, "Which media to download. Must be one of: images, attachments, all")
+	addFlags(Command.Flags())
 }
 
-func isValidMediaSelection(selection crawling.MediaSelection) bool {
-	switch selection {
-	case crawling.MediaSelectionImages, crawling.MediaSelectionAttachments, crawling.MediaSelectionAll:
-		return true
-	default:
-		return false
+func addFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&argConfigFile, "config", "", argConfigFile, "The config file to read defaults and creators from (default is config.yaml in the user config directory)")
+	flags.StringVarP(&argCookie, "cookie", "c", argCookie, "The cookie to use for authentication")
+	flags.StringVarP(&argCookiesFile, "cookies-file", "", argCookiesFile, "A Netscape cookies.txt or JSON cookie export to read the patreon.com cookies from")
+	flags.StringVarP(&argDownloadDir, "download-dir", "d", argDownloadDir, "The directory to download posts to")
+	flags.IntVarP(&argDownloadLimit, "download-limit", "l", argDownloadLimit, "The maximum number of posts to download per creator")
+	flags.BoolVarP(&argDownloadInaccessibleMedia, "download-inaccessible-media", "", argDownloadInaccessibleMedia, "Whether to download inaccessible media")
+	flags.StringVarP(&argGroupingStrategy, "grouping", "g", argGroupingStrategy, "The grouping strategy to use. Must be one of: none, by-post")
+	flags.IntVarP(&argConcurrencyLimit, "concurrency", "", argConcurrencyLimit, "The number of concurrent downloads across all creators")
+	flags.IntVarP(&argConcurrencyPerHost, "concurrency-per-host", "", argConcurrencyPerHost, "The maximum number of concurrent downloads from the same host (0 is unlimited)")
+	flags.StringVarP(&argLimitRate, "limit-rate", "", argLimitRate, "The maximum download bandwidth across all downloads, e.g. 5MB/s")
+	flags.StringVarP(&argLimitRatePerDownload, "limit-rate-per-download", "", argLimitRatePerDownload, "The maximum download bandwidth of each download, e.g. 1MB/s")
+	flags.StringSliceVarP(&argLimitRateSchedule, "limit-rate-schedule", "", argLimitRateSchedule, "Daily windows overriding --limit-rate, e.g. 01:00-07:00=unlimited")
+	flags.IntVarP(&argCreatorConcurrency, "creator-concurrency", "", argCreatorConcurrency, "The number of creators to crawl concurrently")
+	flags.StringVarP(&argMediaSelection, "media", "m", argMediaSelection, "Which media to download. Must be one of: images, attachments, all")
+	flags.StringVarP(&argSince, "since", "", argSince, "Only download posts published on or after this date (YYYY-MM-DD or RFC 3339)")
+	flags.StringVarP(&argUntil, "until", "", argUntil, "Only download posts published on or before this date (YYYY-MM-DD or RFC 3339)")
+	flags.StringVarP(&argTitleMatch, "title-match", "", argTitleMatch, "Only download posts whose title matches this regular expression")
+	flags.StringVarP(&argExcludeTitle, "exclude-title", "", argExcludeTitle, "Skip posts whose title matches this regular expression")
+	flags.StringSliceVarP(&argPostTypes, "post-type", "", argPostTypes, "Only download posts of these types, e.g. image_file, text_only, video_embed")
+	flags.StringSliceVarP(&argTags, "tag", "", argTags, "Only download posts with any of these tags")
+	flags.StringSliceVarP(&argMimeTypes, "mime-type", "", argMimeTypes, "Only download media whose mime type matches one of these glob patterns, e.g. image/*")
+	flags.StringVarP(&argMinSize, "min-size", "", argMinSize, "Skip media smaller than this size, e.g. 100KB")
+	flags.StringVarP(&argMaxSize, "max-size", "", argMaxSize, "Skip media larger than this size, e.g. 500MB")
+	flags.IntVarP(&argMinWidth, "min-width", "", argMinWidth, "Skip images narrower than this many pixels")
+	flags.IntVarP(&argMinHeight, "min-height", "", argMinHeight, "Skip images shorter than this many pixels")
+	flags.StringSliceVarP(&argExtensions, "extension", "", argExtensions, "Only download media with these file extensions")
+	flags.StringSliceVarP(&argExcludeExtensions, "exclude-extension", "", argExcludeExtensions, "Skip media with these file extensions")
+	flags.IntVarP(&argMaxPosts, "max-posts", "", argMaxPosts, "The maximum number of new posts to download across all creators (0 is unlimited)")
+	flags.IntVarP(&argMaxMedia, "max-media", "", argMaxMedia, "The maximum number of new media files to download across all creators (0 is unlimited)")
+	flags.StringVarP(&argMaxBytes, "max-bytes", "", argMaxBytes, "The maximum number of bytes to download across all creators, e.g. 10GB")
+	flags.BoolVarP(&argAllMemberships, "all-memberships", "", argAllMemberships, "Whether to crawl all creators the current user is a member of")
+	flags.StringSliceVarP(&argIncludeCreators, "include-creator", "", argIncludeCreators, "Only crawl memberships whose creator ID matches one of these glob patterns")
+	flags.StringSliceVarP(&argExcludeCreators, "exclude-creator", "", argExcludeCreators, "Skip memberships whose creator ID matches one of these glob patterns")
+	flags.StringSliceVarP(&argWebhookURLs, "webhook-url", "", argWebhookURLs, "Send new posts, download failures and authentication failures as JSON to these URLs")
+	flags.StringSliceVarP(&argDiscordWebhookURLs, "discord-webhook-url", "", argDiscordWebhookURLs, "Send new posts, download failures and authentication failures to these Discord webhooks")
+	flags.StringSliceVarP(&argSlackWebhookURLs, "slack-webhook-url", "", argSlackWebhookURLs, "Send new posts, download failures and authentication failures to these Slack webhooks")
+	flags.StringVarP(&argOnNewPost, "on-new-post", "", argOnNewPost, "A shell command to run for every new post. The post is passed in PATREON_* environment variables and as JSON on stdin")
+}
+
+func newNotifier() notification.Notifier {
+	var notifiers notification.Notifiers
+	for _, url := range argWebhookURLs {
+		notifiers = append(notifiers, notification.NewWebhookNotifier(url, notification.WebhookFormatJSON, cmdutils.Proxy()))
+	}
+	for _, url := range argDiscordWebhookURLs {
+		notifiers = append(notifiers, notification.NewWebhookNotifier(url, notification.WebhookFormatDiscord, cmdutils.Proxy()))
+	}
+	for _, url := range argSlackWebhookURLs {
+		notifiers = append(notifiers, notification.NewWebhookNotifier(url, notification.WebhookFormatSlack, cmdutils.Proxy()))
 	}
+	if argOnNewPost != "" {
+		notifiers = append(notifiers, notification.NewExecNotifier(argOnNewPost))
+	}
+	return notifiers
+}
+
+type crawlOptions struct {
+	downloadDir               string
+	downloadLimit             int
+	downloadInaccessibleMedia bool
+	groupingStrategy          crawling.GroupingStrategy
+	mediaSelection            crawling.MediaSelection
+	postFilter                filter.PostFilter
+	mediaFilter               filter.MediaFilter
 }
 
-func isValidGroupingStrategy(strategy crawling.GroupingStrategy) bool {
-	switch strategy {
-	case crawling.GroupingStrategyNone, crawling.GroupingStrategyByPost:
-		return true
-	default:
-		return false
+func flagOptions(flags *pflag.FlagSet) config.Options {
+	var options config.Options
+	if flags.Changed("download-dir") {
+		options.DownloadDir = &argDownloadDir
+	}
+	if flags.Changed("download-limit") {
+		options.DownloadLimit = &argDownloadLimit
+	}
+	if flags.Changed("download-inaccessible-media") {
+		options.DownloadInaccessibleMedia = &argDownloadInaccessibleMedia
+	}
+	if flags.Changed("grouping") {
+		options.Grouping = &argGroupingStrategy
+	}
+	if flags.Changed("media") {
+		options.Media = &argMediaSelection
+	}
+	if flags.Changed("since") {
+		options.Since = &argSince
+	}
+	if flags.Changed("until") {
+		options.Until = &argUntil
 	}
+	if flags.Changed("title-match") {
+		options.TitleMatch = &argTitleMatch
+	}
+	if flags.Changed("exclude-title") {
+		options.ExcludeTitle = &argExcludeTitle
+	}
+	if flags.Changed("post-type") {
+		options.PostTypes = argPostTypes
+	}
+	if flags.Changed("tag") {
+		options.Tags = argTags
+	}
+	if flags.Changed("mime-type") {
+		options.MimeTypes = argMimeTypes
+	}
+	if flags.Changed("min-size") {
+		options.MinSize = &argMinSize
+	}
+	if flags.Changed("max-size") {
+		options.MaxSize = &argMaxSize
+	}
+	if flags.Changed("min-width") {
+		options.MinWidth = &argMinWidth
+	}
+	if flags.Changed("min-height") {
+		options.MinHeight = &argMinHeight
+	}
+	if flags.Changed("extension") {
+		options.Extensions = argExtensions
+	}
+	if flags.Changed("exclude-extension") {
+		options.ExcludeExtensions = argExcludeExtensions
+	}
+	return options
 }
 
-func cookieCacheFile() (string, error) {
-	usr, err := user.Current()
-	if err != nil {
-		return "", err
+// resolveCrawlOptions layers the defaults, the config file, the creator's
+// profile and the flags.
+func resolveCrawlOptions(creatorID string, cfg config.Config, profileDefaults config.Options, flags *pflag.FlagSet) (crawlOptions, error) {
+	options := config.Options{
+		DownloadDir:               &argDownloadDir,
+		DownloadLimit:             &argDownloadLimit,
+		DownloadInaccessibleMedia: &argDownloadInaccessibleMedia,
+		Grouping:                  &argGroupingStrategy,
+		Media:                     &argMediaSelection,
+		Since:                     &argSince,
+		Until:                     &argUntil,
+		TitleMatch:                &argTitleMatch,
+		ExcludeTitle:              &argExcludeTitle,
+		PostTypes:                 argPostTypes,
+		Tags:                      argTags,
+		MimeTypes:                 argMimeTypes,
+		MinSize:                   &argMinSize,
+		MaxSize:                   &argMaxSize,
+		MinWidth:                  &argMinWidth,
+		MinHeight:                 &argMinHeight,
+		Extensions:                argExtensions,
+		ExcludeExtensions:         argExcludeExtensions,
 	}
-	tokenCacheDir := filepath.Join(usr.HomeDir, ".credentials")
-	err = os.MkdirAll(tokenCacheDir, 0700)
+	options = options.
+		Merge(cfg.Defaults).
+		Merge(profileDefaults).
+		Merge(cfg.CreatorOverrides(creatorID)).
+		Merge(flagOptions(flags))
+
+	if *options.DownloadLimit < 0 {
+		return crawlOptions{}, fmt.Errorf("download limit must be non-negative")
+	}
+
+	groupingStrategy := crawling.GroupingStrategyNone
+	if *options.Grouping != "" {
+		groupingStrategy = crawling.GroupingStrategy(*options.Grouping)
+	}
+	if !cmdutils.IsValidGroupingStrategy(groupingStrategy) {
+		return crawlOptions{}, fmt.Errorf("invalid grouping strategy. Must be one of: none, by-post")
+	}
+
+	mediaSelection := crawling.MediaSelection(*options.Media)
+	if !cmdutils.IsValidMediaSelection(mediaSelection) {
+		return crawlOptions{}, fmt.Errorf("invalid media selection. Must be one of: images, attachments, all")
+	}
+
+	postFilter, err := resolvePostFilter(options)
 	if err != nil {
-		return "", err
+		return crawlOptions{}, err
 	}
-	return filepath.Join(tokenCacheDir,
-		url.QueryEscape("patreon.cookie")), nil
-}
 
-func saveCookieToFile(cookie string) error {
-	cookieFile, err := cookieCacheFile()
+	mediaFilter, err := resolveMediaFilter(options)
 	if err != nil {
-		return err
+		return crawlOptions{}, err
 	}
-	return os.WriteFile(cookieFile, []byte(cookie), 0600)
+
+	return crawlOptions{
+		downloadDir:               *options.DownloadDir,
+		downloadLimit:             *options.DownloadLimit,
+		downloadInaccessibleMedia: *options.DownloadInaccessibleMedia,
+		groupingStrategy:          groupingStrategy,
+		mediaSelection:            mediaSelection,
+		postFilter:                postFilter,
+		mediaFilter:               mediaFilter,
+	}, nil
 }
 
-func readCookieFromFile() (string, error) {
-	cookieFile, err := cookieCacheFile()
+func resolvePostFilter(options config.Options) (filter.PostFilter, error) {
+	since, err := filter.ParseSince(*options.Since)
 	if err != nil {
-		return "", err
+		return filter.PostFilter{}, err
 	}
-
-	cookie, err := os.ReadFile(cookieFile)
+	until, err := filter.ParseUntil(*options.Until)
+	if err != nil {
+		return filter.PostFilter{}, err
+	}
+	titleMatch, err := filter.ParseRegexp(*options.TitleMatch)
 	if err != nil {
-		return "", err
+		return filter.PostFilter{}, err
 	}
-	return string(cookie), nil
+	excludeTitle, err := filter.ParseRegexp(*options.ExcludeTitle)
+	if err != nil {
+		return filter.PostFilter{}, err
+	}
+
+	return filter.PostFilter{
+		Since:        since,
+		Until:        until,
+		TitleMatch:   titleMatch,
+		ExcludeTitle: excludeTitle,
+		PostTypes:    options.PostTypes,
+		Tags:         options.Tags,
+	}, nil
+}
+
+func parseOptionalSize(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return byteutils.ParseSize(value)
 }
 
-func readCookieFromStdin() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-	cookie, err := reader.ReadString('\n')
+func resolveMediaFilter(options config.Options) (filter.MediaFilter, error) {
+	err := filter.ValidateMimeTypes(options.MimeTypes)
 	if err != nil {
-		return "", err
+		return filter.MediaFilter{}, err
+	}
+	minSize, err := parseOptionalSize(*options.MinSize)
+	if err != nil {
+		return filter.MediaFilter{}, fmt.Errorf("invalid minimum size: %w", err)
+	}
+	maxSize, err := parseOptionalSize(*options.MaxSize)
+	if err != nil {
+		return filter.MediaFilter{}, fmt.Errorf("invalid maximum size: %w", err)
+	}
+	if maxSize > 0 && minSize > maxSize {
+		return filter.MediaFilter{}, fmt.Errorf("minimum size must not be larger than maximum size")
+	}
+	if *options.MinWidth < 0 || *options.MinHeight < 0 {
+		return filter.MediaFilter{}, fmt.Errorf("minimum dimensions must be non-negative")
 	}
-	return strings.TrimSpace(cookie), nil
+
+	return filter.MediaFilter{
+		MimeTypes:         options.MimeTypes,
+		MinSizeBytes:      minSize,
+		MaxSizeBytes:      maxSize,
+		MinWidth:          *options.MinWidth,
+		MinHeight:         *options.MinHeight,
+		Extensions:        options.Extensions,
+		ExcludeExtensions: options.ExcludeExtensions,
+	}, nil
 }
 
-func getAPIClientFromStdIn() (api.Client, string, error) {
-	var apiClient api.Client
-	var cookie string
-	var err error
-	authenticated := false
+type budgetLimits struct {
+	maxPosts int
+	maxMedia int
+	maxBytes int64
+}
 
-	for !authenticated {
-		fmt.Println("Please enter your cookie from the patreon website: ")
-		cookie, err = readCookieFromStdin()
-		if err != nil {
-			return nil, "", err
-		}
-		apiClient = api.NewClient(cookie)
-		authenticated, err = apiClient.IsAuthenticated()
-		if err != nil {
-			return nil, "", err
-		}
-		if !authenticated {
-			fmt.Println("Unable to authenticate with the provided cookie. Please try again.")
-		}
+func resolveBudget(cfg config.Config, flags *pflag.FlagSet) (budgetLimits, error) {
+	maxPosts := argMaxPosts
+	if cfg.MaxPosts != nil && !flags.Changed("max-posts") {
+		maxPosts = *cfg.MaxPosts
+	}
+	maxMedia := argMaxMedia
+	if cfg.MaxMedia != nil && !flags.Changed("max-media") {
+		maxMedia = *cfg.MaxMedia
+	}
+	maxBytesValue := argMaxBytes
+	if cfg.MaxBytes != nil && !flags.Changed("max-bytes") {
+		maxBytesValue = *cfg.MaxBytes
+	}
+
+	if maxPosts < 0 || maxMedia < 0 {
+		return budgetLimits{}, fmt.Errorf("maximum posts and media must be non-negative")
+	}
+	maxBytes, err := parseOptionalSize(maxBytesValue)
+	if err != nil {
+		return budgetLimits{}, fmt.Errorf("invalid maximum bytes: %w", err)
 	}
 
-	return apiClient, cookie, nil
+	return budgetLimits{maxPosts: maxPosts, maxMedia: maxMedia, maxBytes: maxBytes}, nil
 }
 
-func getAPIClient(cookie string) (api.Client, error) {
-	if cookie != "" {
-		apiClient := api.NewClient(cookie)
-		authenticated, err := apiClient.IsAuthenticated()
-		if err != nil {
-			return nil, err
-		}
-		if authenticated {
-			return apiClient, nil
-		}
-		return nil, fmt.Errorf("failed to authenticate with cookie provided via --cookie")
-	}
+type rateLimits struct {
+	global      int64
+	perDownload int64
+	schedule    download.RateSchedule
+}
 
-	cookie, err := readCookieFromFile()
-	if err == nil {
-		apiClient := api.NewClient(cookie)
-		authenticated, err := apiClient.IsAuthenticated()
-		if err != nil {
-			return nil, err
-		}
-		if authenticated {
-			return apiClient, nil
-		}
+func resolveRateLimits(cfg config.Config, flags *pflag.FlagSet) (rateLimits, error) {
+	limitRate := argLimitRate
+	if cfg.LimitRate != nil && !flags.Changed("limit-rate") {
+		limitRate = *cfg.LimitRate
+	}
+	limitRatePerDownload := argLimitRatePerDownload
+	if cfg.LimitRatePerDownload != nil && !flags.Changed("limit-rate-per-download") {
+		limitRatePerDownload = *cfg.LimitRatePerDownload
+	}
+	limitRateSchedule := argLimitRateSchedule
+	if !flags.Changed("limit-rate-schedule") {
+		limitRateSchedule = cfg.LimitRateSchedule
 	}
 
-	apiClient, cookie, err := getAPIClientFromStdIn()
+	global, err := cmdutils.ParseRateLimit(limitRate)
 	if err != nil {
-		return nil, err
+		return rateLimits{}, fmt.Errorf("invalid rate limit: %w", err)
 	}
-
-	err = saveCookieToFile(cookie)
+	perDownload, err := cmdutils.ParseRateLimit(limitRatePerDownload)
 	if err != nil {
-		return nil, err
+		return rateLimits{}, fmt.Errorf("invalid rate limit per download: %w", err)
 	}
-
-	return apiClient, nil
+	schedule, err := download.ParseRateSchedule(limitRateSchedule)
+	if err != nil {
+		return rateLimits{}, fmt.Errorf("invalid rate limit schedule: %w", err)
+	}
+	return rateLimits{global: global, perDownload: perDownload, schedule: schedule}, nil
 }
 
-func getDownloadDir(defaultDownloadDir string) (string, error) {
-	if defaultDownloadDir != "" {
-		return defaultDownloadDir, nil
+func loadConfig(flags *pflag.FlagSet) (config.Config, error) {
+	if flags.Changed("config") {
+		return config.Load(argConfigFile, true)
 	}
+	return loadProfileConfig(cmdutils.ActiveProfile())
+}
 
-	fmt.Println("Please enter the download directory: ")
-	reader := bufio.NewReader(os.Stdin)
-	downloadDir, err := reader.ReadString('\n')
+func loadProfileConfig(profile string) (config.Config, error) {
+	if profile != "" {
+		err := config.ValidateProfileName(profile)
+		if err != nil {
+			return config.Config{}, err
+		}
+	}
+	configFile, err := config.DefaultPath(profile)
 	if err != nil {
-		return "", fmt.Errorf("failed to read download directory: %w", err)
+		// Without a user config directory there is no default config to read
+		return config.Config{}, nil
 	}
-	downloadDir = strings.TrimSpace(downloadDir)
-	return downloadDir, nil
+	return config.Load(configFile, false)
 }
 
 var Command = &cobra.Command{
-	Use:   "crawl <creator-id> [<creator-id-2> <creator-id-3> ...]",
+	Use:   "crawl [<creator-id> <creator-id-2> ...]",
 	Short: "Crawl a patreon creator and download their posts",
-	Args:  cobra.MinimumNArgs(1),
-	PreRunE: func(cmd *cobra.Command, args []string) error {
-		if argDownloadLimit < 0 {
-			return fmt.Errorf("download limit must be non-negative")
-		}
-		if argConcurrencyLimit <= 0 {
-			return fmt.Errorf("concurrency limit must be positive")
-		}
-		if argGroupingStrategy != "" && !isValidGroupingStrategy(crawling.GroupingStrategy(argGroupingStrategy)) {
-			return fmt.Errorf("invalid grouping strategy. Must be one of: none, by-post")
-		}
-		if !isValidMediaSelection(crawling.MediaSelection(argMediaSelection)) {
-			return fmt.Errorf("invalid media selection. Must be one of: images, attachments, all")
-		}
-		return nil
-	},
+	Long:  "Crawl one or more patreon creators and download their posts. Without arguments, all creators from the config file are crawled.",
+	Args:  cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var groupingStrategy crawling.GroupingStrategy
-		if argGroupingStrategy == "" {
-			groupingStrategy = crawling.GroupingStrategyNone
-		} else {
-			groupingStrategy = crawling.GroupingStrategy(argGroupingStrategy)
-		}
-
-		downloadDir, err := getDownloadDir(argDownloadDir)
-		if err != nil {
-			return fmt.Errorf("failed to get download directory: %w", err)
-		}
-
-		apiClient, err := getAPIClient(argCookie)
-		if err != nil {
-			return fmt.Errorf("failed to get API client: %w", err)
-		}
-
-		downloader, err := crawling.NewDownloader(downloadDir, argConcurrencyLimit, groupingStrategy)
-		if err != nil {
-			return fmt.Errorf("failed to create downloader: %w", err)
-		}
-
-		for index, creatorID := range args {
-			if index > 0 {
-				fmt.Println()
-			}
-
-			fmt.Printf("Crawling creator %s:\n", color.GreenString(creatorID))
-			err = crawlCreator(creatorID, apiClient, downloader, argDownloadLimit, argDownloadInaccessibleMedia, crawling.MediaSelection(argMediaSelection))
-			if err != nil {
-				return fmt.Errorf("failed to crawl creator %s: %w", creatorID, err)
-			}
-		}
+		ctx, stop := interruptContext()
+		defer stop()
 
-		return nil
+		r := &runner{notifier: newNotifier()}
+		defer r.close()
+		return r.run(ctx, cmd.Flags(), args)
 	},
 }
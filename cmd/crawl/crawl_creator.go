@@ -2,129 +2,243 @@ package crawl
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"os/signal"
-	"slices"
+	"errors"
+	"log/slog"
 	"sync"
+	"time"
 
+	"github.com/MatthiasHarzer/patreon-crawler/cmd/cmdutils"
 	"github.com/MatthiasHarzer/patreon-crawler/crawling"
 	"github.com/MatthiasHarzer/patreon-crawler/crawling/download"
+	"github.com/MatthiasHarzer/patreon-crawler/notification"
 	"github.com/MatthiasHarzer/patreon-crawler/patreon"
 	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+	"github.com/MatthiasHarzer/patreon-crawler/queue"
 	"github.com/fatih/color"
 )
 
-type mediaPair struct {
-	post  patreon.Post
-	media patreon.Media
+type discovery struct {
+	mediaPairs []crawling.PostMedia
+	newestPost time.Time
+	// complete is false if the download limit or budget stopped discovery.
+	complete bool
 }
 
-func selectMedia(post patreon.Post, mediaSelection crawling.MediaSelection) []patreon.Media {
-	switch mediaSelection {
-	case crawling.MediaSelectionAttachments:
-		return post.Attachments
-	case crawling.MediaSelectionAll:
-		return dedupeMediaByID(slices.Concat(post.Media, post.Attachments))
-	default:
-		return post.Media
-	}
-}
-
-// dedupeMediaByID removes media sharing an ID, keeping the first occurrence. A
-// post can reference the same media in both its Media and Attachments, which
-// would otherwise cause it to be downloaded twice under "all".
-func dedupeMediaByID(media []patreon.Media) []patreon.Media {
-	seen := make(map[string]struct{}, len(media))
-	result := make([]patreon.Media, 0, len(media))
-	for _, m := range media {
-		if _, ok := seen[m.ID]; ok {
-			continue
-		}
-		seen[m.ID] = struct{}{}
-		result = append(result, m)
-	}
-	return result
-}
-
-func crawlMediaPairs(client patreon.Client, downloadInaccessibleMedia bool, downloadLimit int, mediaSelection crawling.MediaSelection) ([]mediaPair, error) {
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
-	defer stop()
-
-	var mediaPairs []mediaPair
+func crawlMediaPairs(ctx context.Context, client patreon.Client, downloader *crawling.Downloader, budget *crawling.Budget, options crawlOptions, out creatorOutput) (discovery, error) {
+	var mediaPairs []crawling.PostMedia
+	var newestPost time.Time
+	complete := true
 	totalPostsDiscovered := 0
 	inaccessiblePostsSkipped := 0
+	filteredPostsSkipped := 0
+	filteredMediaSkipped := 0
+	postsSelected := 0
+	budgetExhausted := false
+	var reserved [][]patreon.Media
+	releaseReserved := func() {
+		for _, media := range reserved {
+			budget.Release(media)
+		}
+	}
 	for post, err := range client.Posts() {
 		if ctx.Err() != nil {
-			fmt.Println()
-			return nil, fmt.Errorf("crawling interrupted")
+			out.abortProgress()
+			releaseReserved()
+			return discovery{}, errInterrupted
 		}
 		if err != nil {
-			return nil, err
+			out.abortProgress()
+			releaseReserved()
+			return discovery{}, err
+		}
+		out.progress("Discovered %s posts with %s media files.", color.GreenString("%d", totalPostsDiscovered), color.GreenString("%d", len(mediaPairs)))
+
+		if options.postFilter.IsPastRange(post) {
+			// Posts are sorted newest first, so all remaining posts are out of range
+			slog.Debug("stopping pagination at post before --since", "post", post.ID, "published_at", post.PublishedAt)
+			break
 		}
-		fmt.Printf("\rDiscovered %s posts with %s media files.", color.GreenString("%d", totalPostsDiscovered), color.GreenString("%d", len(mediaPairs)))
 		totalPostsDiscovered++
+		if post.PublishedAt.After(newestPost) {
+			newestPost = post.PublishedAt
+		}
+
+		if !options.postFilter.Matches(post) {
+			filteredPostsSkipped++
+			continue
+		}
 
-		if !post.CurrentUserCanView && !downloadInaccessibleMedia {
+		if !post.CurrentUserCanView && !options.downloadInaccessibleMedia {
 			inaccessiblePostsSkipped++
 			continue
 		}
 
-		for _, media := range selectMedia(post, mediaSelection) {
-			mediaPairs = append(mediaPairs, mediaPair{post: post, media: media})
+		selectedMedia := crawling.SelectMedia(post, options.mediaSelection)
+		filteredMedia := options.mediaFilter.Apply(selectedMedia)
+		filteredMediaSkipped += len(selectedMedia) - len(filteredMedia)
+		if len(filteredMedia) == 0 {
+			continue
 		}
 
-		if downloadLimit > 0 && len(mediaPairs) >= downloadLimit {
-			if len(mediaPairs) > downloadLimit {
-				mediaPairs = mediaPairs[:downloadLimit]
+		var pendingMedia []patreon.Media
+		for _, media := range filteredMedia {
+			if !downloader.IsDownloaded(client.CreatorID(), post, media) {
+				pendingMedia = append(pendingMedia, media)
 			}
+		}
+		if !budget.Reserve(pendingMedia) {
+			slog.Debug("stopping pagination as post exceeds the budget", "post", post.ID, "media", len(pendingMedia))
+			budgetExhausted = true
+			complete = false
+			break
+		}
+		reserved = append(reserved, pendingMedia)
+
+		for _, media := range filteredMedia {
+			mediaPairs = append(mediaPairs, crawling.PostMedia{Post: post, Media: media})
+		}
+
+		postsSelected++
+		if options.downloadLimit > 0 && postsSelected >= options.downloadLimit {
+			complete = false
 			break
 		}
 	}
 
-	fmt.Printf("\rDiscovered %s posts with %s media files.\n", color.GreenString("%d", totalPostsDiscovered), color.GreenString("%d", len(mediaPairs)))
+	out.endProgress("Discovered %s posts with %s media files.", color.GreenString("%d", totalPostsDiscovered), color.GreenString("%d", len(mediaPairs)))
+	if filteredPostsSkipped > 0 {
+		out.Printf("Skipped %s posts not matching the filters.\n", color.YellowString("%d", filteredPostsSkipped))
+	}
+	if filteredMediaSkipped > 0 {
+		out.Printf("Skipped %s media files not matching the filters.\n", color.YellowString("%d", filteredMediaSkipped))
+	}
 	if inaccessiblePostsSkipped > 0 {
-		fmt.Printf("Skipped %s inaccessible posts.\n", color.YellowString("%d", inaccessiblePostsSkipped))
+		out.Printf("Skipped %s inaccessible posts.\n", color.YellowString("%d", inaccessiblePostsSkipped))
 	}
-	return mediaPairs, nil
+	if budgetExhausted {
+		out.Printf("%s Remaining posts are skipped.\n", color.YellowString("Download budget reached."))
+	}
+	return discovery{
+		mediaPairs: mediaPairs,
+		newestPost: newestPost,
+		complete:   complete,
+	}, nil
 }
 
-func crawlCreator(creatorID string, apiClient api.Client, downloader *crawling.Downloader, downloadLimit int, downloadInaccessibleMedia bool, mediaSelection crawling.MediaSelection) error {
-	client, err := patreon.NewClient(apiClient, creatorID)
-	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+type postProgress struct {
+	mutex      sync.Mutex
+	pending    map[string]int
+	downloaded map[string]int
+}
+
+func newPostProgress(mediaPairs []crawling.PostMedia) *postProgress {
+	pending := make(map[string]int)
+	for _, pair := range mediaPairs {
+		pending[pair.Post.ID]++
+	}
+	return &postProgress{
+		pending:    pending,
+		downloaded: make(map[string]int),
+	}
+}
+
+func (p *postProgress) report(postID string, reportItem download.ReportItem) (int, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := reportItem.(*download.ReportSuccessItem); ok {
+		p.downloaded[postID]++
 	}
+	p.pending[postID]--
+	return p.downloaded[postID], p.pending[postID] == 0
+}
 
-	vanityID := client.VanityID()
+type creatorCrawler struct {
+	apiClient      api.Client
+	downloadQueue  *crawling.DownloadQueue
+	downloader     *crawling.Downloader
+	budget         *crawling.Budget
+	notifier       notification.Notifier
+	out            creatorOutput
+	reauthenticate func() error
+}
 
-	mediaPairs, err := crawlMediaPairs(client, downloadInaccessibleMedia, downloadLimit, mediaSelection)
+// crawl returns the zero time unless all matching posts were downloaded.
+func (c *creatorCrawler) crawl(ctx context.Context, campaign patreon.Campaign, options crawlOptions) (time.Time, error) {
+	client := patreon.NewCampaignClient(c.apiClient, campaign)
+	creatorID := client.CreatorID()
+
+	discovered, err := crawlMediaPairs(ctx, client, c.downloader, c.budget, options, c.out)
+	if errors.Is(err, api.ErrUnauthenticated) {
+		c.out.Println(color.YellowString("The patreon cookie has expired."))
+		err = c.reauthenticate()
+		if err != nil {
+			return time.Time{}, err
+		}
+		c.out.Println("Discovering posts again...")
+		discovered, err = crawlMediaPairs(ctx, client, c.downloader, c.budget, options, c.out)
+	}
 	if err != nil {
-		return err
+		return time.Time{}, err
 	}
 
-	fmt.Println("Downloading media...")
+	c.out.Println("Downloading media...")
 
-	printMutex := sync.Mutex{}
-	for _, pair := range mediaPairs {
-		pair := pair
-		downloader.Enqueue(vanityID, pair.post, pair.media, func(reportItem download.ReportItem) {
-			printMutex.Lock()
-			defer printMutex.Unlock()
-			switch item := reportItem.(type) {
-			case *download.ReportErrorItem:
-				fmt.Printf("[%s] %s from post \"%s\": %s\n", color.RedString("error"), item.Media.ID, color.RedString(pair.post.Title), item.Err)
-			case *download.ReportSkippedItem:
-				fmt.Printf("[%s] %s from post \"%s\" (%s)\n", color.YellowString("skipped"), item.Media.ID, color.YellowString(pair.post.Title), color.RGB(100, 100, 100).Sprint(item.Reason))
-			case *download.ReportSuccessItem:
-				fmt.Printf("[%s] %s from post \"%s\"\n", color.GreenString("downloaded"), item.Media.ID, color.GreenString(pair.post.Title))
-			}
-		})
+	guard := &authGuard{
+		apiClient:      c.apiClient,
+		downloadQueue:  c.downloadQueue,
+		downloader:     c.downloader,
+		reauthenticate: c.reauthenticate,
+		out:            c.out,
 	}
+	c.downloader.SetRefresher(guard.refresh)
+	c.downloader.EnqueueAll(creatorID, discovered.mediaPairs)
+	c.downloader.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.out.Println("Interrupted. Finishing downloads in progress...")
+			c.downloader.Stop()
+		case <-done:
+		}
+	}()
+
+	failedDownloads := 0
+	progress := newPostProgress(discovered.mediaPairs)
+	printReportItem := cmdutils.NewReportPrinter(c.out.prefix)
+	for result := range c.downloader.Results() {
+		if result.Err != nil {
+			// Returned by Wait below
+			continue
+		}
+		post, media, reportItem := result.Value.Post, result.Value.Media, result.Value.Report
+		if item, ok := reportItem.(*download.ReportErrorItem); ok {
+			failedDownloads++
+			notification.Send(ctx, c.notifier, notification.DownloadFailedEvent(creatorID, post, media, item.Err))
+		}
+		printReportItem(post, reportItem)
 
-	err = downloader.ProcessAll()
+		downloaded, done := progress.report(post.ID, reportItem)
+		if done && downloaded > 0 {
+			notification.Send(ctx, c.notifier, notification.NewPostEvent(creatorID, post, downloaded))
+		}
+	}
+
+	err = c.downloader.Wait()
+	if guard.err != nil {
+		return time.Time{}, guard.err
+	}
+	if errors.Is(err, queue.ErrStopped) {
+		return time.Time{}, errInterrupted
+	}
 	if err != nil {
-		return err
+		return time.Time{}, err
 	}
 
-	return nil
+	if !discovered.complete || failedDownloads > 0 {
+		return time.Time{}, nil
+	}
+	return discovered.newestPost, nil
 }
@@ -0,0 +1,53 @@
+package crawl
+
+import (
+	"fmt"
+)
+
+// creatorOutput prefixes lines with the creator and omits in-place progress
+// updates if creators are crawled concurrently.
+type creatorOutput struct {
+	prefix string
+}
+
+func newCreatorOutput(creatorID string, concurrent bool) creatorOutput {
+	if !concurrent {
+		return creatorOutput{}
+	}
+	return creatorOutput{prefix: fmt.Sprintf("[%s] ", creatorID)}
+}
+
+func (o creatorOutput) concurrent() bool {
+	return o.prefix != ""
+}
+
+// Printf requires the format to end with a newline.
+func (o creatorOutput) Printf(format string, a ...any) {
+	fmt.Print(o.prefix + fmt.Sprintf(format, a...))
+}
+
+func (o creatorOutput) Println(a ...any) {
+	fmt.Print(o.prefix + fmt.Sprintln(a...))
+}
+
+func (o creatorOutput) progress(format string, a ...any) {
+	if o.concurrent() {
+		return
+	}
+	fmt.Printf("\r"+format, a...)
+}
+
+func (o creatorOutput) endProgress(format string, a ...any) {
+	if o.concurrent() {
+		o.Printf(format+"\n", a...)
+		return
+	}
+	fmt.Printf("\r"+format+"\n", a...)
+}
+
+func (o creatorOutput) abortProgress() {
+	if o.concurrent() {
+		return
+	}
+	fmt.Println()
+}
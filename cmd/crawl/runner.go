@@ -0,0 +1,487 @@
+package crawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/cmd/cmdutils"
+	"github.com/MatthiasHarzer/patreon-crawler/config"
+	"github.com/MatthiasHarzer/patreon-crawler/crawling"
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/download"
+	"github.com/MatthiasHarzer/patreon-crawler/notification"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+	"github.com/fatih/color"
+	"github.com/spf13/pflag"
+)
+
+var errInterrupted = errors.New("crawling interrupted")
+
+// interruptContext lets a second signal terminate the process immediately.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+type runner struct {
+	notifier notification.Notifier
+	// apiClients stores the client of the active profile with the empty key.
+	apiClients               map[string]api.Client
+	promptedDownloadDir      string
+	downloadQueue            *crawling.DownloadQueue
+	downloadQueueConcurrency int
+	downloadClient           *http.Client
+	downloadClientMaxPerHost int
+	rateLimiter              *download.Limiter
+	campaigns                map[string]patreon.Campaign
+	budget                   *crawling.Budget
+	budgetStart              time.Time
+	budgetPeriod             time.Duration
+	authMutex                sync.Mutex
+	// newestPosts holds the newest post per creator of the last complete run. If
+	// not nil, only newer posts are crawled.
+	newestPosts      map[string]time.Time
+	newestPostsMutex sync.Mutex
+}
+
+// getAPIClient treats the empty profile as the active profile, which also
+// honors --cookie and --cookies-file.
+func (r *runner) getAPIClient(ctx context.Context, profile string) (api.Client, error) {
+	if apiClient, ok := r.apiClients[profile]; ok {
+		return apiClient, nil
+	}
+
+	var apiClient api.Client
+	var err error
+	if profile == "" {
+		apiClient, err = cmdutils.GetAPIClient(argCookie, argCookiesFile)
+	} else {
+		apiClient, err = cmdutils.GetProfileAPIClient(profile)
+	}
+	if err != nil {
+		notification.Send(ctx, r.notifier, notification.AuthFailedEvent(err))
+		return nil, err
+	}
+
+	if r.apiClients == nil {
+		r.apiClients = make(map[string]api.Client)
+	}
+	r.apiClients[profile] = apiClient
+	return apiClient, nil
+}
+
+func (r *runner) reauthenticate(ctx context.Context, profile string) error {
+	r.authMutex.Lock()
+	defer r.authMutex.Unlock()
+	// Another creator of the profile may have replaced the cookie meanwhile
+	authenticated, err := r.apiClients[profile].IsAuthenticated()
+	if err != nil {
+		return err
+	}
+	if authenticated {
+		return nil
+	}
+
+	cookie, err := cmdutils.Reauthenticate(profile)
+	if err != nil {
+		notification.Send(ctx, r.notifier, notification.AuthFailedEvent(err))
+		return err
+	}
+	r.apiClients[profile].SetCookie(cookie)
+	return nil
+}
+
+func creatorProfile(cfg config.Config, creatorID string) string {
+	profile := cfg.CreatorProfile(creatorID)
+	if profile == cmdutils.ActiveProfile() {
+		return ""
+	}
+	return profile
+}
+
+func (r *runner) getDownloadDir() (string, error) {
+	if r.promptedDownloadDir != "" {
+		return r.promptedDownloadDir, nil
+	}
+	downloadDir, err := cmdutils.PromptDownloadDir()
+	if err != nil {
+		return "", err
+	}
+	r.promptedDownloadDir = downloadDir
+	return downloadDir, nil
+}
+
+func (r *runner) applyNewestPost(creatorID string, options crawlOptions) crawlOptions {
+	r.newestPostsMutex.Lock()
+	defer r.newestPostsMutex.Unlock()
+	if r.newestPosts == nil {
+		return options
+	}
+	newestPost, ok := r.newestPosts[strings.ToLower(creatorID)]
+	if ok && newestPost.After(options.postFilter.Since) {
+		options.postFilter.Since = newestPost
+	}
+	return options
+}
+
+func (r *runner) recordNewestPost(creatorID string, newestPost time.Time) {
+	r.newestPostsMutex.Lock()
+	defer r.newestPostsMutex.Unlock()
+	if r.newestPosts == nil || newestPost.IsZero() {
+		return
+	}
+	r.newestPosts[strings.ToLower(creatorID)] = newestPost
+}
+
+func (r *runner) getDownloadQueue(concurrencyLimit int) (*crawling.DownloadQueue, error) {
+	if r.downloadQueue != nil && r.downloadQueueConcurrency == concurrencyLimit {
+		return r.downloadQueue, nil
+	}
+	downloadQueue, err := crawling.NewDownloadQueue(concurrencyLimit)
+	if err != nil {
+		return nil, err
+	}
+	r.close()
+	downloadQueue.Start()
+	r.downloadQueue = downloadQueue
+	r.downloadQueueConcurrency = concurrencyLimit
+	return downloadQueue, nil
+}
+
+func (r *runner) getDownloadClient(maxPerHost int) *http.Client {
+	if r.downloadClient == nil || r.downloadClientMaxPerHost != maxPerHost {
+		r.downloadClient = download.NewClient(maxPerHost, cmdutils.Proxy())
+		r.downloadClientMaxPerHost = maxPerHost
+	}
+	return r.downloadClient
+}
+
+// startThrottle lets the global rate follow the schedule until ctx is done.
+func (r *runner) startThrottle(ctx context.Context, limits rateLimits) download.Throttle {
+	if r.rateLimiter == nil {
+		r.rateLimiter = download.NewLimiter(0)
+	}
+	r.rateLimiter.SetRate(limits.schedule.RateAt(time.Now(), limits.global))
+
+	if len(limits.schedule) > 0 {
+		go func() {
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C:
+					rate := limits.schedule.RateAt(now, limits.global)
+					if rate != r.rateLimiter.Rate() {
+						slog.Debug("changing rate limit", "rate", rate)
+						r.rateLimiter.SetRate(rate)
+					}
+				}
+			}
+		}()
+	}
+
+	return download.Throttle{Global: r.rateLimiter, PerDownload: limits.perDownload}
+}
+
+func (r *runner) getBudget(limits budgetLimits) *crawling.Budget {
+	now := time.Now()
+	if r.budget == nil || r.budgetPeriod <= 0 || !now.Before(r.budgetStart.Add(r.budgetPeriod)) {
+		r.budget = crawling.NewBudget(limits.maxPosts, limits.maxMedia, limits.maxBytes)
+		r.budgetStart = now
+		return r.budget
+	}
+	r.budget.SetLimits(limits.maxPosts, limits.maxMedia, limits.maxBytes)
+	return r.budget
+}
+
+func (r *runner) close() {
+	if r.downloadQueue != nil {
+		r.downloadQueue.Close()
+		r.downloadQueue = nil
+	}
+}
+
+type crawlTarget struct {
+	campaign patreon.Campaign
+	profile  string
+}
+
+func appendTarget(targets []crawlTarget, target crawlTarget) []crawlTarget {
+	if slices.ContainsFunc(targets, func(t crawlTarget) bool { return t.campaign.ID == target.campaign.ID }) {
+		return targets
+	}
+	return append(targets, target)
+}
+
+func (r *runner) resolveCampaign(ctx context.Context, profile, creator string) (patreon.Campaign, error) {
+	key := profile + "/" + strings.ToLower(creator)
+	if campaign, ok := r.campaigns[key]; ok {
+		return campaign, nil
+	}
+
+	apiClient, err := r.getAPIClient(ctx, profile)
+	if err != nil {
+		return patreon.Campaign{}, fmt.Errorf("failed to get API client: %w", err)
+	}
+	campaign, err := patreon.ResolveCampaign(apiClient, creator)
+	if err != nil {
+		return patreon.Campaign{}, fmt.Errorf("failed to resolve creator %s: %w", creator, err)
+	}
+	slog.Debug("resolved campaign", "creator", creator, "campaign", campaign.ID, "vanity", campaign.Vanity)
+
+	if r.campaigns == nil {
+		r.campaigns = make(map[string]patreon.Campaign)
+	}
+	r.campaigns[key] = campaign
+	return campaign, nil
+}
+
+// resolveCreators uses the profile configured for the creator as passed,
+// falling back to the profile configured for its vanity or campaign ID.
+func (r *runner) resolveCreators(ctx context.Context, cfg config.Config, creators []string) ([]crawlTarget, error) {
+	var targets []crawlTarget
+	for _, creator := range creators {
+		profile := creatorProfile(cfg, creator)
+		campaign, err := r.resolveCampaign(ctx, profile, creator)
+		if err != nil {
+			return nil, err
+		}
+		if profile == "" {
+			profile = creatorProfile(cfg, campaign.CreatorID())
+		}
+		targets = appendTarget(targets, crawlTarget{campaign: campaign, profile: profile})
+	}
+	return targets, nil
+}
+
+func (r *runner) run(ctx context.Context, flags *pflag.FlagSet, args []string) error {
+	cfg, err := loadConfig(flags)
+	if err != nil {
+		return err
+	}
+
+	creators := args
+	if len(creators) == 0 {
+		creators = cfg.CreatorIDs()
+	}
+	targets, err := r.resolveCreators(ctx, cfg, creators)
+	if err != nil {
+		return err
+	}
+
+	allMemberships := argAllMemberships
+	if cfg.AllMemberships != nil && !flags.Changed("all-memberships") {
+		allMemberships = *cfg.AllMemberships
+	}
+	if len(targets) == 0 && !allMemberships {
+		return fmt.Errorf("no creators specified. Pass creator IDs as arguments, configure them in the config file or use --all-memberships")
+	}
+
+	limits, err := resolveBudget(cfg, flags)
+	if err != nil {
+		return err
+	}
+	budget := r.getBudget(limits)
+
+	if allMemberships {
+		apiClient, err := r.getAPIClient(ctx, "")
+		if err != nil {
+			return fmt.Errorf("failed to get API client: %w", err)
+		}
+
+		includeCreators := argIncludeCreators
+		if !flags.Changed("include-creator") {
+			includeCreators = cfg.IncludeCreators
+		}
+		excludeCreators := argExcludeCreators
+		if !flags.Changed("exclude-creator") {
+			excludeCreators = cfg.ExcludeCreators
+		}
+
+		campaigns, err := cmdutils.MembershipCampaigns(apiClient, includeCreators, excludeCreators)
+		if err != nil {
+			return fmt.Errorf("failed to get memberships: %w", err)
+		}
+		for _, campaign := range campaigns {
+			targets = appendTarget(targets, crawlTarget{campaign: campaign, profile: creatorProfile(cfg, campaign.CreatorID())})
+		}
+	}
+
+	if len(targets) == 0 {
+		return fmt.Errorf("no memberships found matching the include and exclude patterns")
+	}
+
+	concurrencyLimit := argConcurrencyLimit
+	if cfg.Concurrency != nil && !flags.Changed("concurrency") {
+		concurrencyLimit = *cfg.Concurrency
+	}
+	if concurrencyLimit <= 0 {
+		return fmt.Errorf("concurrency limit must be positive")
+	}
+
+	concurrencyPerHost := argConcurrencyPerHost
+	if cfg.ConcurrencyPerHost != nil && !flags.Changed("concurrency-per-host") {
+		concurrencyPerHost = *cfg.ConcurrencyPerHost
+	}
+	if concurrencyPerHost < 0 {
+		return fmt.Errorf("concurrency per host must not be negative")
+	}
+
+	rateLimits, err := resolveRateLimits(cfg, flags)
+	if err != nil {
+		return err
+	}
+
+	profileConfigs := make(map[string]config.Config)
+	creatorOptions := make([]crawlOptions, len(targets))
+	for i, target := range targets {
+		creatorID, profile := target.campaign.CreatorID(), target.profile
+
+		var profileDefaults config.Options
+		if profile != "" {
+			profileConfig, ok := profileConfigs[profile]
+			if !ok {
+				profileConfig, err = loadProfileConfig(profile)
+				if err != nil {
+					return fmt.Errorf("failed to load config of profile %s: %w", profile, err)
+				}
+				profileConfigs[profile] = profileConfig
+			}
+			profileDefaults = profileConfig.Defaults
+		}
+
+		creatorOptions[i], err = resolveCrawlOptions(creatorID, cfg, profileDefaults, flags)
+		if err != nil {
+			return fmt.Errorf("invalid options for creator %s: %w", creatorID, err)
+		}
+	}
+
+	apiClients := make([]api.Client, len(targets))
+	for i, target := range targets {
+		apiClients[i], err = r.getAPIClient(ctx, target.profile)
+		if err != nil {
+			return fmt.Errorf("failed to get API client for creator %s: %w", target.campaign.CreatorID(), err)
+		}
+	}
+
+	for i := range creatorOptions {
+		if creatorOptions[i].downloadDir != "" {
+			continue
+		}
+		creatorOptions[i].downloadDir, err = r.getDownloadDir()
+		if err != nil {
+			return fmt.Errorf("failed to get download directory: %w", err)
+		}
+	}
+
+	creatorConcurrency := argCreatorConcurrency
+	if cfg.CreatorConcurrency != nil && !flags.Changed("creator-concurrency") {
+		creatorConcurrency = *cfg.CreatorConcurrency
+	}
+	if creatorConcurrency <= 0 {
+		return fmt.Errorf("creator concurrency must be positive")
+	}
+
+	// All creators share the download queue, so --concurrency limits the
+	// downloads of the whole run.
+	downloadQueue, err := r.getDownloadQueue(concurrencyLimit)
+	if err != nil {
+		return err
+	}
+	downloadClient := r.getDownloadClient(concurrencyPerHost)
+	throttleCtx, stopThrottle := context.WithCancel(ctx)
+	defer stopThrottle()
+	throttle := r.startThrottle(throttleCtx, rateLimits)
+
+	concurrent := creatorConcurrency > 1 && len(targets) > 1
+	slots := make(chan struct{}, creatorConcurrency)
+	var wg sync.WaitGroup
+	var errMutex sync.Mutex
+	var errs []error
+	failed := func() bool {
+		errMutex.Lock()
+		defer errMutex.Unlock()
+		return len(errs) > 0
+	}
+
+	for index, target := range targets {
+		creatorID := target.campaign.CreatorID()
+		slots <- struct{}{}
+		// No further creators are started after a creator failed
+		if failed() {
+			break
+		}
+		if index > 0 && !concurrent {
+			fmt.Println()
+		}
+
+		out := newCreatorOutput(creatorID, concurrent)
+		if budget.Exhausted() {
+			out.Printf("Download budget reached. Skipping creator %s.\n", color.YellowString(creatorID))
+			<-slots
+			continue
+		}
+
+		crawler := &creatorCrawler{
+			apiClient: apiClients[index],
+			budget:    budget,
+			notifier:  r.notifier,
+			out:       out,
+			reauthenticate: func() error {
+				return r.reauthenticate(ctx, target.profile)
+			},
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			err := r.crawlCreator(ctx, crawler, downloadQueue, downloadClient, throttle, target.campaign, creatorOptions[index])
+			if err != nil {
+				errMutex.Lock()
+				errs = append(errs, err)
+				errMutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (r *runner) crawlCreator(ctx context.Context, crawler *creatorCrawler, downloadQueue *crawling.DownloadQueue, downloadClient *http.Client, throttle download.Throttle, campaign patreon.Campaign, options crawlOptions) error {
+	creatorID := campaign.CreatorID()
+	options = r.applyNewestPost(creatorID, options)
+	crawler.downloadQueue = downloadQueue
+	crawler.downloader = crawling.NewDownloader(options.downloadDir, downloadQueue, downloadClient, throttle, crawler.budget, options.groupingStrategy)
+
+	if crawler.out.concurrent() {
+		crawler.out.Println("Crawling creator...")
+	} else {
+		fmt.Printf("Crawling creator %s:\n", color.GreenString(creatorID))
+	}
+	newestPost, err := crawler.crawl(ctx, campaign, options)
+	if errors.Is(err, errInterrupted) || errors.Is(err, api.ErrUnauthenticated) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to crawl creator %s: %w", creatorID, err)
+	}
+	r.recordNewestPost(creatorID, newestPost)
+	return nil
+}
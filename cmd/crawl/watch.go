@@ -0,0 +1,88 @@
+package crawl
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var argInterval = time.Hour
+var argJitter = 5 * time.Minute
+var argOnce bool
+var argBudgetPeriod = 24 * time.Hour
+
+func init() {
+	addFlags(WatchCommand.Flags())
+	WatchCommand.Flags().DurationVarP(&argInterval, "interval", "", argInterval, "The time to wait between syncs")
+	WatchCommand.Flags().DurationVarP(&argJitter, "jitter", "", argJitter, "The maximum random time added to the interval")
+	WatchCommand.Flags().BoolVarP(&argOnce, "once", "", argOnce, "Sync once and exit")
+	WatchCommand.Flags().DurationVarP(&argBudgetPeriod, "budget-period", "", argBudgetPeriod, "The period --max-posts, --max-media and --max-bytes apply to across syncs (0 applies them per sync)")
+}
+
+func nextSyncDelay() time.Duration {
+	if argJitter <= 0 {
+		return argInterval
+	}
+	return argInterval + rand.N(argJitter)
+}
+
+var WatchCommand = &cobra.Command{
+	Use:   "watch [<creator-id> <creator-id-2> ...]",
+	Short: "Periodically crawl patreon creators and download new posts",
+	Long:  "Periodically crawl one or more patreon creators and download their new posts. Accepts the same arguments, flags and config file as crawl. The config file is reloaded before every sync.",
+	Args:  cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argInterval <= 0 {
+			return fmt.Errorf("interval must be positive")
+		}
+		if argJitter < 0 {
+			return fmt.Errorf("jitter must not be negative")
+		}
+		if argBudgetPeriod < 0 {
+			return fmt.Errorf("budget period must not be negative")
+		}
+
+		ctx, stop := interruptContext()
+		defer stop()
+
+		r := &runner{
+			notifier:     newNotifier(),
+			newestPosts:  make(map[string]time.Time),
+			budgetPeriod: argBudgetPeriod,
+		}
+		defer r.close()
+		for {
+			err := r.run(ctx, cmd.Flags(), args)
+			if errors.Is(err, errInterrupted) {
+				fmt.Println("Stopped watching.")
+				return nil
+			}
+			if err != nil {
+				// An expired cookie cannot recover without user interaction
+				if argOnce || errors.Is(err, api.ErrUnauthenticated) {
+					return err
+				}
+				fmt.Printf("%s %v\n", color.RedString("Sync failed:"), err)
+			}
+			if argOnce {
+				return nil
+			}
+
+			delay := nextSyncDelay()
+			nextSync := time.Now().Add(delay)
+			fmt.Printf("\nNext sync at %s.\n", color.GreenString(nextSync.Format(time.DateTime)))
+			select {
+			case <-ctx.Done():
+				fmt.Println("Stopped watching.")
+				return nil
+			case <-time.After(delay):
+			}
+			fmt.Println()
+		}
+	},
+}
@@ -0,0 +1,153 @@
+package creators
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/cmd/cmdutils"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/spf13/cobra"
+)
+
+type outputFormat string
+
+const (
+	outputFormatTable outputFormat = "table"
+	outputFormatJSON  outputFormat = "json"
+)
+
+var argCookie string
+var argCookiesFile string
+var argIncludeCreators []string
+var argExcludeCreators []string
+var argFormat = string(outputFormatTable)
+
+func init() {
+	Command.Flags().StringVarP(&argCookie, "cookie", "c", argCookie, "The cookie to use for authentication")
+	Command.Flags().StringVarP(&argCookiesFile, "cookies-file", "", argCookiesFile, "A Netscape cookies.txt or JSON cookie export to read the patreon.com cookies from")
+	Command.Flags().StringSliceVarP(&argIncludeCreators, "include-creator", "", argIncludeCreators, "Only list memberships whose creator ID matches one of these glob patterns")
+	Command.Flags().StringSliceVarP(&argExcludeCreators, "exclude-creator", "", argExcludeCreators, "Skip memberships whose creator ID matches one of these glob patterns")
+	Command.Flags().StringVarP(&argFormat, "format", "f", argFormat, "The output format. Must be one of: table, json")
+}
+
+type membershipOutput struct {
+	Name              string     `json:"name"`
+	Vanity            string     `json:"vanity"`
+	CampaignID        string     `json:"campaign_id"`
+	URL               string     `json:"url"`
+	PatronStatus      string     `json:"patron_status"`
+	PledgeAmountCents int        `json:"pledge_amount_cents"`
+	Currency          string     `json:"currency"`
+	Tiers             []string   `json:"tiers"`
+	AccessExpiresAt   *time.Time `json:"access_expires_at"`
+}
+
+func newMembershipOutput(membership patreon.Membership) membershipOutput {
+	var accessExpiresAt *time.Time
+	if !membership.AccessExpiresAt.IsZero() {
+		accessExpiresAt = &membership.AccessExpiresAt
+	}
+	tiers := membership.Tiers
+	if tiers == nil {
+		tiers = []string{}
+	}
+	return membershipOutput{
+		Name:              membership.Campaign.Name,
+		Vanity:            membership.Campaign.Vanity,
+		CampaignID:        membership.Campaign.ID,
+		URL:               membership.Campaign.URL,
+		PatronStatus:      membership.PatronStatus,
+		PledgeAmountCents: membership.PledgeAmountCents,
+		Currency:          membership.Currency,
+		Tiers:             tiers,
+		AccessExpiresAt:   accessExpiresAt,
+	}
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func printTable(memberships []patreon.Membership) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "NAME\tVANITY\tURL\tSTATUS\tPLEDGE\tTIER\tACCESS EXPIRES")
+	for _, membership := range memberships {
+		pledge := "-"
+		if membership.PledgeAmountCents > 0 {
+			pledge = fmt.Sprintf("%.2f %s", float64(membership.PledgeAmountCents)/100, membership.Currency)
+		}
+		accessExpires := "-"
+		if !membership.AccessExpiresAt.IsZero() {
+			accessExpires = membership.AccessExpiresAt.Local().Format(time.DateOnly)
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			valueOrDash(membership.Campaign.Name),
+			valueOrDash(membership.Campaign.Vanity),
+			valueOrDash(membership.Campaign.URL),
+			valueOrDash(membership.PatronStatus),
+			pledge,
+			valueOrDash(strings.Join(membership.Tiers, ", ")),
+			accessExpires,
+		)
+	}
+	return writer.Flush()
+}
+
+func printJSON(memberships []patreon.Membership) error {
+	output := make([]membershipOutput, 0, len(memberships))
+	for _, membership := range memberships {
+		output = append(output, newMembershipOutput(membership))
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+var Command = &cobra.Command{
+	Use:     "creators",
+	Aliases: []string{"list-creators"},
+	Short:   "List the creators the current user is a member of",
+	Args:    cobra.NoArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		switch outputFormat(argFormat) {
+		case outputFormatTable, outputFormatJSON:
+			return nil
+		default:
+			return fmt.Errorf("invalid output format. Must be one of: table, json")
+		}
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiClient, err := cmdutils.GetAPIClient(argCookie, argCookiesFile)
+		if err != nil {
+			return fmt.Errorf("failed to get API client: %w", err)
+		}
+
+		memberships, err := patreon.GetMemberships(apiClient)
+		if err != nil {
+			return fmt.Errorf("failed to get memberships: %w", err)
+		}
+
+		var filtered []patreon.Membership
+		for _, membership := range memberships {
+			matches, err := patreon.MatchesCreatorPatterns(membership.Campaign.Vanity, argIncludeCreators, argExcludeCreators)
+			if err != nil {
+				return err
+			}
+			if matches {
+				filtered = append(filtered, membership)
+			}
+		}
+
+		if outputFormat(argFormat) == outputFormatJSON {
+			return printJSON(filtered)
+		}
+		return printTable(filtered)
+	},
+}
@@ -0,0 +1,140 @@
+package get
+
+import (
+	"fmt"
+
+	"github.com/MatthiasHarzer/patreon-crawler/cmd/cmdutils"
+	"github.com/MatthiasHarzer/patreon-crawler/crawling"
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/download"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var argCookie string
+var argCookiesFile string
+var argDownloadDir string
+var argDownloadInaccessibleMedia bool
+var argGroupingStrategy string
+var argConcurrencyLimit = 4
+var argConcurrencyPerHost int
+var argLimitRate string
+var argLimitRatePerDownload string
+var argMediaSelection = string(crawling.MediaSelectionImages)
+
+func init() {
+	Command.Flags().StringVarP(&argCookie, "cookie", "c", argCookie, "The cookie to use for authentication")
+	Command.Flags().StringVarP(&argCookiesFile, "cookies-file", "", argCookiesFile, "A Netscape cookies.txt or JSON cookie export to read the patreon.com cookies from")
+	Command.Flags().StringVarP(&argDownloadDir, "download-dir", "d", argDownloadDir, "The directory to download posts to")
+	Command.Flags().BoolVarP(&argDownloadInaccessibleMedia, "download-inaccessible-media", "", argDownloadInaccessibleMedia, "Whether to download inaccessible media")
+	Command.Flags().StringVarP(&argGroupingStrategy, "grouping", "g", argGroupingStrategy, "The grouping strategy to use. Must be one of: none, by-post")
+	Command.Flags().IntVarP(&argConcurrencyLimit, "concurrency", "", argConcurrencyLimit, "The number of concurrent downloads")
+	Command.Flags().IntVarP(&argConcurrencyPerHost, "concurrency-per-host", "", argConcurrencyPerHost, "The maximum number of concurrent downloads from the same host (0 is unlimited)")
+	Command.Flags().StringVarP(&argLimitRate, "limit-rate", "", argLimitRate, "The maximum download bandwidth across all downloads, e.g. 5MB/s")
+	Command.Flags().StringVarP(&argLimitRatePerDownload, "limit-rate-per-download", "", argLimitRatePerDownload, "The maximum download bandwidth of each download, e.g. 1MB/s")
+	Command.Flags().StringVarP(&argMediaSelection, "media", "m", argMediaSelection, "Which media to download. Must be one of: images, attachments, all")
+}
+
+var Command = &cobra.Command{
+	Use:   "get <post-url|post-id> [<post-url-2|post-id-2> ...]",
+	Short: "Download individual patreon posts",
+	Args:  cobra.MinimumNArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if argConcurrencyLimit <= 0 {
+			return fmt.Errorf("concurrency limit must be positive")
+		}
+		if argConcurrencyPerHost < 0 {
+			return fmt.Errorf("concurrency per host must not be negative")
+		}
+		if argGroupingStrategy != "" && !cmdutils.IsValidGroupingStrategy(crawling.GroupingStrategy(argGroupingStrategy)) {
+			return fmt.Errorf("invalid grouping strategy. Must be one of: none, by-post")
+		}
+		if !cmdutils.IsValidMediaSelection(crawling.MediaSelection(argMediaSelection)) {
+			return fmt.Errorf("invalid media selection. Must be one of: images, attachments, all")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		postIDs := make([]string, 0, len(args))
+		for _, arg := range args {
+			postID, err := patreon.ParsePostID(arg)
+			if err != nil {
+				return err
+			}
+			postIDs = append(postIDs, postID)
+		}
+
+		groupingStrategy := crawling.GroupingStrategyNone
+		if argGroupingStrategy != "" {
+			groupingStrategy = crawling.GroupingStrategy(argGroupingStrategy)
+		}
+
+		downloadDir := argDownloadDir
+		if downloadDir == "" {
+			var err error
+			downloadDir, err = cmdutils.PromptDownloadDir()
+			if err != nil {
+				return fmt.Errorf("failed to get download directory: %w", err)
+			}
+		}
+
+		limitRate, err := cmdutils.ParseRateLimit(argLimitRate)
+		if err != nil {
+			return fmt.Errorf("invalid rate limit: %w", err)
+		}
+		limitRatePerDownload, err := cmdutils.ParseRateLimit(argLimitRatePerDownload)
+		if err != nil {
+			return fmt.Errorf("invalid rate limit per download: %w", err)
+		}
+		throttle := download.Throttle{Global: download.NewLimiter(limitRate), PerDownload: limitRatePerDownload}
+
+		apiClient, err := cmdutils.GetAPIClient(argCookie, argCookiesFile)
+		if err != nil {
+			return fmt.Errorf("failed to get API client: %w", err)
+		}
+
+		downloadQueue, err := crawling.NewDownloadQueue(argConcurrencyLimit)
+		if err != nil {
+			return err
+		}
+		downloadQueue.Start()
+		defer downloadQueue.Close()
+		downloader := crawling.NewDownloader(downloadDir, downloadQueue, download.NewClient(argConcurrencyPerHost, cmdutils.Proxy()), throttle, nil, groupingStrategy)
+
+		printReportItem := cmdutils.NewReportPrinter("")
+		printed := make(chan struct{})
+		go func() {
+			defer close(printed)
+			for result := range downloader.Results() {
+				if result.Err == nil {
+					printReportItem(result.Value.Post, result.Value.Report)
+				}
+			}
+		}()
+
+		for _, postID := range postIDs {
+			post, campaign, err := patreon.GetPost(apiClient, postID)
+			if err != nil {
+				downloader.Stop()
+				return fmt.Errorf("failed to get post %s: %w", postID, err)
+			}
+
+			if !post.CurrentUserCanView && !argDownloadInaccessibleMedia {
+				fmt.Printf("Skipping inaccessible post \"%s\".\n", color.YellowString(post.Title))
+				continue
+			}
+
+			media := crawling.SelectMedia(post, crawling.MediaSelection(argMediaSelection))
+			fmt.Printf("Found post \"%s\" by %s with %s media files.\n", color.GreenString(post.Title), color.GreenString(campaign.Vanity), color.GreenString("%d", len(media)))
+			downloads := make([]crawling.PostMedia, 0, len(media))
+			for _, m := range media {
+				downloads = append(downloads, crawling.PostMedia{Post: post, Media: m})
+			}
+			downloader.EnqueueAll(campaign.Vanity, downloads)
+		}
+
+		downloader.Close()
+		<-printed
+		return downloader.Wait()
+	},
+}
@@ -0,0 +1,129 @@
+package posts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/cmd/cmdutils"
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/filter"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/spf13/cobra"
+)
+
+var argCookie string
+var argCookiesFile string
+var argSince string
+var argUntil string
+var argAccessibleOnly bool
+var argHasAttachments bool
+var argJSON bool
+
+func init() {
+	Command.Flags().StringVarP(&argCookie, "cookie", "c", argCookie, "The cookie to use for authentication")
+	Command.Flags().StringVarP(&argCookiesFile, "cookies-file", "", argCookiesFile, "A Netscape cookies.txt or JSON cookie export to read the patreon.com cookies from")
+	Command.Flags().StringVarP(&argSince, "since", "", argSince, "Only list posts published on or after this date (YYYY-MM-DD or RFC 3339)")
+	Command.Flags().StringVarP(&argUntil, "until", "", argUntil, "Only list posts published on or before this date (YYYY-MM-DD or RFC 3339)")
+	Command.Flags().BoolVarP(&argAccessibleOnly, "accessible-only", "", argAccessibleOnly, "Only list posts the current user can view")
+	Command.Flags().BoolVarP(&argHasAttachments, "has-attachments", "", argHasAttachments, "Only list posts with attachments")
+	Command.Flags().BoolVarP(&argJSON, "json", "", argJSON, "Print the posts as JSON")
+}
+
+type postOutput struct {
+	ID              string    `json:"id"`
+	PublishedAt     time.Time `json:"published_at"`
+	Title           string    `json:"title"`
+	Accessible      bool      `json:"accessible"`
+	MediaCount      int       `json:"media_count"`
+	AttachmentCount int       `json:"attachment_count"`
+}
+
+func newPostOutput(post patreon.Post) postOutput {
+	return postOutput{
+		ID:              post.ID,
+		PublishedAt:     post.PublishedAt,
+		Title:           post.Title,
+		Accessible:      post.CurrentUserCanView,
+		MediaCount:      len(post.Media),
+		AttachmentCount: len(post.Attachments),
+	}
+}
+
+func printTable(posts []postOutput) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "ID\tDATE\tTITLE\tACCESSIBLE\tMEDIA\tATTACHMENTS")
+	for _, post := range posts {
+		accessible := "no"
+		if post.Accessible {
+			accessible = "yes"
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%d\n",
+			post.ID,
+			post.PublishedAt.Local().Format(time.DateOnly),
+			post.Title,
+			accessible,
+			post.MediaCount,
+			post.AttachmentCount,
+		)
+	}
+	return writer.Flush()
+}
+
+func printJSON(posts []postOutput) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(posts)
+}
+
+var Command = &cobra.Command{
+	Use:   "posts <creator-id>",
+	Short: "List the posts of a patreon creator without downloading them",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := filter.ParseSince(argSince)
+		if err != nil {
+			return err
+		}
+		until, err := filter.ParseUntil(argUntil)
+		if err != nil {
+			return err
+		}
+		postFilter := filter.PostFilter{
+			Since:          since,
+			Until:          until,
+			AccessibleOnly: argAccessibleOnly,
+			HasAttachments: argHasAttachments,
+		}
+
+		apiClient, err := cmdutils.GetAPIClient(argCookie, argCookiesFile)
+		if err != nil {
+			return fmt.Errorf("failed to get API client: %w", err)
+		}
+
+		client, err := patreon.NewClient(apiClient, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		posts := make([]postOutput, 0)
+		for post, err := range client.Posts() {
+			if err != nil {
+				return fmt.Errorf("failed to get posts: %w", err)
+			}
+			if postFilter.IsPastRange(post) {
+				break
+			}
+			if !postFilter.Matches(post) {
+				continue
+			}
+			posts = append(posts, newPostOutput(post))
+		}
+
+		if argJSON {
+			return printJSON(posts)
+		}
+		return printTable(posts)
+	},
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Options fields are nil if unset, so they can be told apart from zero values
+// when layering defaults, creator overrides and flags.
+type Options struct {
+	DownloadDir               *string  `yaml:"download-dir"`
+	DownloadLimit             *int     `yaml:"download-limit"`
+	DownloadInaccessibleMedia *bool    `yaml:"download-inaccessible-media"`
+	Grouping                  *string  `yaml:"grouping"`
+	Media                     *string  `yaml:"media"`
+	Since                     *string  `yaml:"since"`
+	Until                     *string  `yaml:"until"`
+	TitleMatch                *string  `yaml:"title-match"`
+	ExcludeTitle              *string  `yaml:"exclude-title"`
+	PostTypes                 []string `yaml:"post-types"`
+	Tags                      []string `yaml:"tags"`
+	MimeTypes                 []string `yaml:"mime-types"`
+	MinSize                   *string  `yaml:"min-size"`
+	MaxSize                   *string  `yaml:"max-size"`
+	MinWidth                  *int     `yaml:"min-width"`
+	MinHeight                 *int     `yaml:"min-height"`
+	Extensions                []string `yaml:"extensions"`
+	ExcludeExtensions         []string `yaml:"exclude-extensions"`
+}
+
+type Creator struct {
+	ID string `yaml:"id"`
+	// Profile is empty for the profile the command runs with.
+	Profile string `yaml:"profile"`
+	Options `yaml:",inline"`
+}
+
+type Config struct {
+	Concurrency          *int      `yaml:"concurrency"`
+	CreatorConcurrency   *int      `yaml:"creator-concurrency"`
+	ConcurrencyPerHost   *int      `yaml:"concurrency-per-host"`
+	LimitRate            *string   `yaml:"limit-rate"`
+	LimitRatePerDownload *string   `yaml:"limit-rate-per-download"`
+	LimitRateSchedule    []string  `yaml:"limit-rate-schedule"`
+	MaxPosts             *int      `yaml:"max-posts"`
+	MaxMedia             *int      `yaml:"max-media"`
+	MaxBytes             *string   `yaml:"max-bytes"`
+	AllMemberships       *bool     `yaml:"all-memberships"`
+	IncludeCreators      []string  `yaml:"include-creators"`
+	ExcludeCreators      []string  `yaml:"exclude-creators"`
+	Defaults             Options   `yaml:"defaults"`
+	Creators             []Creator `yaml:"creators"`
+}
+
+func override[T any](base, override *T) *T {
+	if override != nil {
+		return override
+	}
+	return base
+}
+
+func overrideSlice[T any](base, override []T) []T {
+	if override != nil {
+		return override
+	}
+	return base
+}
+
+func (o Options) Merge(other Options) Options {
+	return Options{
+		DownloadDir:               override(o.DownloadDir, other.DownloadDir),
+		DownloadLimit:             override(o.DownloadLimit, other.DownloadLimit),
+		DownloadInaccessibleMedia: override(o.DownloadInaccessibleMedia, other.DownloadInaccessibleMedia),
+		Grouping:                  override(o.Grouping, other.Grouping),
+		Media:                     override(o.Media, other.Media),
+		Since:                     override(o.Since, other.Since),
+		Until:                     override(o.Until, other.Until),
+		TitleMatch:                override(o.TitleMatch, other.TitleMatch),
+		ExcludeTitle:              override(o.ExcludeTitle, other.ExcludeTitle),
+		PostTypes:                 overrideSlice(o.PostTypes, other.PostTypes),
+		Tags:                      overrideSlice(o.Tags, other.Tags),
+		MimeTypes:                 overrideSlice(o.MimeTypes, other.MimeTypes),
+		MinSize:                   override(o.MinSize, other.MinSize),
+		MaxSize:                   override(o.MaxSize, other.MaxSize),
+		MinWidth:                  override(o.MinWidth, other.MinWidth),
+		MinHeight:                 override(o.MinHeight, other.MinHeight),
+		Extensions:                overrideSlice(o.Extensions, other.Extensions),
+		ExcludeExtensions:         overrideSlice(o.ExcludeExtensions, other.ExcludeExtensions),
+	}
+}
+
+func (c Config) CreatorIDs() []string {
+	ids := make([]string, 0, len(c.Creators))
+	for _, creator := range c.Creators {
+		ids = append(ids, creator.ID)
+	}
+	return ids
+}
+
+// CreatorOverrides compares creator IDs case-insensitively.
+func (c Config) CreatorOverrides(creatorID string) Options {
+	var options Options
+	for _, creator := range c.Creators {
+		if strings.EqualFold(creator.ID, creatorID) {
+			options = options.Merge(creator.Options)
+		}
+	}
+	return options
+}
+
+func (c Config) CreatorProfile(creatorID string) string {
+	profile := ""
+	for _, creator := range c.Creators {
+		if strings.EqualFold(creator.ID, creatorID) && creator.Profile != "" {
+			profile = creator.Profile
+		}
+	}
+	return profile
+}
+
+// Dir honors XDG_CONFIG_HOME on all platforms.
+func Dir() (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		var err error
+		configDir, err = os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(configDir, "patreon-crawler"), nil
+}
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func ValidateProfileName(profile string) error {
+	if !profileNamePattern.MatchString(profile) {
+		return fmt.Errorf("invalid profile name %q. Must only contain letters, digits, - and _", profile)
+	}
+	return nil
+}
+
+// ProfileDir returns Dir itself for the empty profile.
+func ProfileDir(profile string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	if profile == "" {
+		return dir, nil
+	}
+	err = ValidateProfileName(profile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "profiles", profile), nil
+}
+
+func DefaultPath(profile string) (string, error) {
+	dir, err := ProfileDir(profile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+func Parse(data []byte) (Config, error) {
+	var config Config
+	err := yaml.Unmarshal(data, &config)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	for i, creator := range config.Creators {
+		if creator.ID == "" {
+			return Config{}, fmt.Errorf("creator at index %d has no id", i)
+		}
+	}
+
+	return config, nil
+}
+
+// Load returns an empty config if the file does not exist and is not
+// required.
+func Load(path string, required bool) (Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return Parse(data)
+}
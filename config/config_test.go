@@ -0,0 +1,135 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MatthiasHarzer/patreon-crawler/config"
+	"github.com/MatthiasHarzer/patreon-crawler/util/fsutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+concurrency: 8
+creator-concurrency: 2
+defaults:
+  download-dir: /data/patreon
+  media: images
+  grouping: by-post
+  tags: [sketch]
+creators:
+  - id: first-creator
+    media: all
+    download-limit: 10
+    since: 2025-01-01
+    tags: [final, wip]
+  - id: second-creator
+    profile: work
+    download-dir: /data/other
+    download-inaccessible-media: true
+`
+
+func TestParse(t *testing.T) {
+	t.Run("parses defaults and creators", func(t *testing.T) {
+		cfg, err := config.Parse([]byte(testConfig))
+		require.NoError(t, err)
+
+		require.NotNil(t, cfg.Concurrency)
+		assert.Equal(t, 8, *cfg.Concurrency)
+		require.NotNil(t, cfg.CreatorConcurrency)
+		assert.Equal(t, 2, *cfg.CreatorConcurrency)
+		assert.Equal(t, "/data/patreon", *cfg.Defaults.DownloadDir)
+		assert.Equal(t, []string{"first-creator", "second-creator"}, cfg.CreatorIDs())
+	})
+
+	t.Run("fails for creators without id", func(t *testing.T) {
+		_, err := config.Parse([]byte("creators:\n  - media: all\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("fails for invalid yaml", func(t *testing.T) {
+		_, err := config.Parse([]byte("creators: ["))
+		assert.Error(t, err)
+	})
+}
+
+func TestCreatorOverrides(t *testing.T) {
+	cfg, err := config.Parse([]byte(testConfig))
+	require.NoError(t, err)
+
+	t.Run("creator overrides take precedence over defaults", func(t *testing.T) {
+		options := cfg.Defaults.Merge(cfg.CreatorOverrides("First-Creator"))
+		assert.Equal(t, "all", *options.Media)
+		assert.Equal(t, 10, *options.DownloadLimit)
+		assert.Equal(t, "by-post", *options.Grouping)
+		assert.Equal(t, "/data/patreon", *options.DownloadDir)
+		assert.Equal(t, "2025-01-01", *options.Since)
+		assert.Equal(t, []string{"final", "wip"}, options.Tags)
+		assert.Nil(t, options.DownloadInaccessibleMedia)
+	})
+
+	t.Run("unknown creators have no overrides", func(t *testing.T) {
+		options := cfg.CreatorOverrides("unknown")
+		assert.Equal(t, config.Options{}, options)
+	})
+}
+
+func TestCreatorProfile(t *testing.T) {
+	cfg, err := config.Parse([]byte(testConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, "work", cfg.CreatorProfile("Second-Creator"))
+	assert.Equal(t, "", cfg.CreatorProfile("first-creator"))
+	assert.Equal(t, "", cfg.CreatorProfile("unknown"))
+}
+
+func TestProfileDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/config")
+
+	t.Run("default profile uses the config dir", func(t *testing.T) {
+		dir, err := config.ProfileDir("")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join("/config", "patreon-crawler"), dir)
+	})
+
+	t.Run("named profiles use a sub directory", func(t *testing.T) {
+		path, err := config.DefaultPath("work")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join("/config", "patreon-crawler", "profiles", "work", "config.yaml"), path)
+	})
+
+	t.Run("rejects names escaping the profiles dir", func(t *testing.T) {
+		_, err := config.ProfileDir("../work")
+		assert.Error(t, err)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("missing optional file returns empty config", func(t *testing.T) {
+		cfg, err := config.Load(filepath.Join(os.TempDir(), "does-not-exist.yaml"), false)
+		require.NoError(t, err)
+		assert.Empty(t, cfg.Creators)
+	})
+
+	t.Run("missing required file fails", func(t *testing.T) {
+		_, err := config.Load(filepath.Join(os.TempDir(), "does-not-exist.yaml"), true)
+		assert.Error(t, err)
+	})
+
+	t.Run("reads file", func(t *testing.T) {
+		dir, cleanup, err := fsutils.TemporaryDirectory()
+		require.NoError(t, err)
+		defer cleanup()
+
+		path := filepath.Join(dir, "config.yaml")
+		err = os.WriteFile(path, []byte(testConfig), 0600)
+		require.NoError(t, err)
+
+		cfg, err := config.Load(path, true)
+		require.NoError(t, err)
+		assert.Len(t, cfg.Creators, 2)
+	})
+}
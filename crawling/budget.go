@@ -0,0 +1,103 @@
+package crawling
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+)
+
+// Budget reserves entire posts, so that a post is never downloaded partially.
+// Posts with media of unknown size are only started while the byte limit is not
+// reached and may exceed it. Limits of 0 are unlimited.
+type Budget struct {
+	mutex    sync.Mutex
+	maxPosts int
+	maxMedia int
+	maxBytes int64
+	posts    int
+	media    int
+	bytes    int64
+}
+
+func NewBudget(maxPosts, maxMedia int, maxBytes int64) *Budget {
+	return &Budget{
+		maxPosts: maxPosts,
+		maxMedia: maxMedia,
+		maxBytes: maxBytes,
+	}
+}
+
+func (b *Budget) SetLimits(maxPosts, maxMedia int, maxBytes int64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.maxPosts = maxPosts
+	b.maxMedia = maxMedia
+	b.maxBytes = maxBytes
+}
+
+func knownSize(media []patreon.Media) int64 {
+	var size int64
+	for _, m := range media {
+		size += m.SizeBytes
+	}
+	return size
+}
+
+func hasUnknownSize(media []patreon.Media) bool {
+	return slices.ContainsFunc(media, func(m patreon.Media) bool { return m.SizeBytes <= 0 })
+}
+
+func (b *Budget) Reserve(media []patreon.Media) bool {
+	if len(media) == 0 {
+		return true
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	size := knownSize(media)
+	if b.maxPosts > 0 && b.posts+1 > b.maxPosts {
+		return false
+	}
+	if b.maxMedia > 0 && b.media+len(media) > b.maxMedia {
+		return false
+	}
+	if b.maxBytes > 0 && b.bytes+size > b.maxBytes {
+		return false
+	}
+	if b.maxBytes > 0 && hasUnknownSize(media) && b.bytes+size >= b.maxBytes {
+		return false
+	}
+
+	b.posts++
+	b.media += len(media)
+	b.bytes += size
+	return true
+}
+
+func (b *Budget) Release(media []patreon.Media) {
+	if len(media) == 0 {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.posts--
+	b.media -= len(media)
+	b.bytes -= knownSize(media)
+}
+
+func (b *Budget) AddDownloadedBytes(size int64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.bytes += size
+}
+
+func (b *Budget) Exhausted() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return (b.maxPosts > 0 && b.posts >= b.maxPosts) ||
+		(b.maxMedia > 0 && b.media >= b.maxMedia) ||
+		(b.maxBytes > 0 && b.bytes >= b.maxBytes)
+}
@@ -0,0 +1,98 @@
+package crawling_test
+
+import (
+	"testing"
+
+	"github.com/MatthiasHarzer/patreon-crawler/crawling"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget(t *testing.T) {
+	small := patreon.Media{ID: "small", SizeBytes: 100}
+	large := patreon.Media{ID: "large", SizeBytes: 1000}
+	unknown := patreon.Media{ID: "unknown"}
+
+	t.Run("unlimited budget reserves everything", func(t *testing.T) {
+		budget := crawling.NewBudget(0, 0, 0)
+		for i := 0; i < 100; i++ {
+			assert.True(t, budget.Reserve([]patreon.Media{small, large}))
+		}
+		assert.False(t, budget.Exhausted())
+	})
+
+	t.Run("max posts", func(t *testing.T) {
+		budget := crawling.NewBudget(2, 0, 0)
+		assert.True(t, budget.Reserve([]patreon.Media{small, small}))
+		assert.True(t, budget.Reserve([]patreon.Media{large}))
+		assert.True(t, budget.Exhausted())
+		assert.False(t, budget.Reserve([]patreon.Media{small}))
+	})
+
+	t.Run("max media never splits a post", func(t *testing.T) {
+		budget := crawling.NewBudget(0, 3, 0)
+		assert.True(t, budget.Reserve([]patreon.Media{small, small}))
+		assert.False(t, budget.Reserve([]patreon.Media{small, small}))
+		assert.False(t, budget.Exhausted())
+		assert.True(t, budget.Reserve([]patreon.Media{small}))
+		assert.True(t, budget.Exhausted())
+	})
+
+	t.Run("max bytes uses known sizes", func(t *testing.T) {
+		budget := crawling.NewBudget(0, 0, 1200)
+		assert.True(t, budget.Reserve([]patreon.Media{large, small}))
+		assert.False(t, budget.Reserve([]patreon.Media{large}))
+		assert.True(t, budget.Reserve([]patreon.Media{small}))
+		assert.True(t, budget.Exhausted())
+	})
+
+	t.Run("max bytes accounts downloaded bytes of unknown sizes", func(t *testing.T) {
+		budget := crawling.NewBudget(0, 0, 1000)
+		assert.True(t, budget.Reserve([]patreon.Media{unknown}))
+		assert.False(t, budget.Exhausted())
+
+		budget.AddDownloadedBytes(950)
+		assert.False(t, budget.Reserve([]patreon.Media{small}))
+		budget.AddDownloadedBytes(50)
+		assert.True(t, budget.Exhausted())
+	})
+
+	t.Run("max bytes only starts posts of unknown size below the limit", func(t *testing.T) {
+		budget := crawling.NewBudget(0, 0, 1000)
+		assert.True(t, budget.Reserve([]patreon.Media{unknown, unknown}))
+		budget.AddDownloadedBytes(600)
+		assert.True(t, budget.Reserve([]patreon.Media{unknown, small}))
+		assert.False(t, budget.Reserve([]patreon.Media{unknown, large}))
+
+		budget.AddDownloadedBytes(1200)
+		assert.False(t, budget.Reserve([]patreon.Media{unknown}))
+	})
+
+	t.Run("release returns reserved budget", func(t *testing.T) {
+		budget := crawling.NewBudget(1, 0, 0)
+		media := []patreon.Media{{ID: "1", SizeBytes: 10}}
+		require.True(t, budget.Reserve(media))
+		require.True(t, budget.Exhausted())
+
+		budget.Release(media)
+		assert.False(t, budget.Exhausted())
+		assert.True(t, budget.Reserve(media))
+	})
+
+	t.Run("set limits keeps reserved budget", func(t *testing.T) {
+		budget := crawling.NewBudget(1, 0, 0)
+		require.True(t, budget.Reserve([]patreon.Media{small}))
+		budget.SetLimits(2, 0, 0)
+		assert.False(t, budget.Exhausted())
+		assert.True(t, budget.Reserve([]patreon.Media{small}))
+		assert.True(t, budget.Exhausted())
+	})
+
+	t.Run("posts without media are not counted", func(t *testing.T) {
+		budget := crawling.NewBudget(1, 0, 0)
+		assert.True(t, budget.Reserve(nil))
+		assert.False(t, budget.Exhausted())
+	})
+}
@@ -0,0 +1,90 @@
+package download
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/util/netutils"
+)
+
+// NewClient has no overall timeout, as media files can be large. If maxPerHost
+// is positive, further requests to a host wait for a free slot.
+func NewClient(maxPerHost int, proxy *url.URL) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := &http.Transport{
+		Proxy:                 netutils.ProxyFunc(proxy),
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   16,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if maxPerHost > 0 {
+		roundTripper = &hostLimitTransport{
+			base:       transport,
+			maxPerHost: maxPerHost,
+			slots:      make(map[string]chan struct{}),
+		}
+	}
+	return &http.Client{Transport: roundTripper}
+}
+
+// hostLimitTransport holds the slot of a request until its body is closed.
+type hostLimitTransport struct {
+	base       http.RoundTripper
+	maxPerHost int
+
+	mutex sync.Mutex
+	slots map[string]chan struct{}
+}
+
+func (t *hostLimitTransport) hostSlots(host string) chan struct{} {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	slots, ok := t.slots[host]
+	if !ok {
+		slots = make(chan struct{}, t.maxPerHost)
+		t.slots[host] = slots
+	}
+	return slots
+}
+
+func (t *hostLimitTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	slots := t.hostSlots(request.URL.Host)
+	select {
+	case slots <- struct{}{}:
+	case <-request.Context().Done():
+		return nil, request.Context().Err()
+	}
+	release := sync.OnceFunc(func() { <-slots })
+
+	response, err := t.base.RoundTrip(request)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	response.Body = &releasingBody{ReadCloser: response.Body, release: release}
+	return response, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,81 @@
+package download_test
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/download"
+	"github.com/MatthiasHarzer/patreon-crawler/util/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	t.Run("limits concurrent requests per host", func(t *testing.T) {
+		var active int32
+		var maxActive int32
+		url, cleanup := testutils.HTTPServer(map[string]http.HandlerFunc{
+			"/media.jpg": func(w http.ResponseWriter, r *http.Request) {
+				current := atomic.AddInt32(&active, 1)
+				for {
+					previous := atomic.LoadInt32(&maxActive)
+					if current <= previous || atomic.CompareAndSwapInt32(&maxActive, previous, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&active, -1)
+				_, _ = w.Write([]byte("content"))
+			},
+		})
+		defer cleanup()
+
+		client := download.NewClient(2, nil)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				response, err := client.Get(url.String() + "media.jpg")
+				if !assert.NoError(t, err) {
+					return
+				}
+				_, _ = io.Copy(io.Discard, response.Body)
+				_ = response.Body.Close()
+			}()
+		}
+		wg.Wait()
+
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxActive), int32(2))
+	})
+	t.Run("sends requests through the proxy", func(t *testing.T) {
+		var requestedHost, proxyAuthorization string
+		proxyURL, cleanup := testutils.HTTPServer(map[string]http.HandlerFunc{
+			"/": func(w http.ResponseWriter, r *http.Request) {
+				requestedHost = r.Host
+				proxyAuthorization = r.Header.Get("Proxy-Authorization")
+				_, _ = w.Write([]byte("content"))
+			},
+		})
+		defer cleanup()
+		proxyURL.User = url.UserPassword("user", "secret")
+
+		client := download.NewClient(0, &proxyURL)
+		response, err := client.Get("http://media.example.com/media.jpg")
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "content", string(body))
+		assert.Equal(t, "media.example.com", requestedHost)
+		assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret")), proxyAuthorization)
+	})
+}
@@ -1,16 +1,22 @@
 package download
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/util/logutils"
 )
 
+// ErrForbidden is also returned if the signed media URL expired.
+var ErrForbidden = errors.New("access to media denied")
+
 func GetMediaFile(downloadDirectory string, media patreon.Media) (string, error) {
 	extension, err := getFileExtension(media.MimeType)
 	if err != nil {
@@ -43,7 +49,7 @@ func adjustFileTime(filePath string, publishedAt time.Time) error {
 	return nil
 }
 
-func Media(media patreon.Media, downloadDir string, modTime time.Time) ReportItem {
+func Media(client *http.Client, throttle Throttle, media patreon.Media, downloadDir string, modTime time.Time) ReportItem {
 	if media.MimeType == "" {
 		return NewSkippedItem(media, "no mime type")
 	}
@@ -62,13 +68,23 @@ func Media(media patreon.Media, downloadDir string, modTime time.Time) ReportIte
 		return NewSkippedItem(media, "already downloaded")
 	}
 
-	response, err := http.Get(media.DownloadURL)
+	logURL := logutils.RedactURL(media.DownloadURL)
+	slog.Debug("downloading media", "media", media.ID, "url", logURL)
+	start := time.Now()
+
+	response, err := client.Get(media.DownloadURL)
 	if err != nil {
+		slog.Warn("media request failed", "media", media.ID, "url", logURL, "error", err)
 		return NewErrorItem(media, err)
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+		slog.Info("media request was denied", "media", media.ID, "url", logURL, "status", response.StatusCode)
+		return NewErrorItem(media, fmt.Errorf("%w: %s", ErrForbidden, response.Status))
+	}
 	if response.StatusCode != http.StatusOK {
+		slog.Info("media request returned unexpected status", "media", media.ID, "url", logURL, "status", response.StatusCode)
 		return NewErrorItem(media, fmt.Errorf("unexpected status code: %s", response.Status))
 	}
 
@@ -85,7 +101,7 @@ func Media(media patreon.Media, downloadDir string, modTime time.Time) ReportIte
 	}
 	defer out.Close()
 
-	_, err = io.Copy(out, response.Body)
+	written, err := io.Copy(out, throttle.reader(response.Body))
 	if err != nil {
 		return NewErrorItem(media, fmt.Errorf("failed to write file: %w", err))
 	}
@@ -102,5 +118,6 @@ func Media(media patreon.Media, downloadDir string, modTime time.Time) ReportIte
 		return NewErrorItem(media, fmt.Errorf("failed to adjust file time: %w", err))
 	}
 
-	return NewSuccessItem(media)
+	slog.Debug("downloaded media", "media", media.ID, "status", response.StatusCode, "bytes", written, "duration", time.Since(start))
+	return NewSuccessItem(media, written)
 }
@@ -50,11 +50,12 @@ func TestDownload(t *testing.T) {
 		defer dirCleanup()
 
 		for _, media := range medias {
-			reportItem := download.Media(media, downloadDir, time.Time{})
+			reportItem := download.Media(http.DefaultClient, download.Throttle{}, media, downloadDir, time.Time{})
 			require.IsType(t, &download.ReportSuccessItem{}, reportItem)
 
 			successItem := reportItem.(*download.ReportSuccessItem)
 			assert.Equal(t, media.ID, successItem.Media.ID)
+			assert.Equal(t, int64(len(media.ID+" content")), successItem.Bytes)
 		}
 
 		downloadDirMedia1Path := fmt.Sprintf("%s/media1.jpeg", downloadDir)
@@ -92,7 +93,7 @@ func TestDownload(t *testing.T) {
 		err = os.WriteFile(downloadedFilePath, []byte("existing content"), 0644)
 		require.NoError(t, err)
 
-		reportItem := download.Media(media, downloadDir, time.Time{})
+		reportItem := download.Media(http.DefaultClient, download.Throttle{}, media, downloadDir, time.Time{})
 		require.IsType(t, &download.ReportSkippedItem{}, reportItem)
 
 		skippedItem := reportItem.(*download.ReportSkippedItem)
@@ -111,7 +112,7 @@ func TestDownload(t *testing.T) {
 		require.NoError(t, err)
 		defer dirCleanup()
 
-		reportItem := download.Media(media, downloadDir, time.Time{})
+		reportItem := download.Media(http.DefaultClient, download.Throttle{}, media, downloadDir, time.Time{})
 		require.IsType(t, &download.ReportSkippedItem{}, reportItem)
 
 		skippedItem := reportItem.(*download.ReportSkippedItem)
@@ -130,7 +131,7 @@ func TestDownload(t *testing.T) {
 		require.NoError(t, err)
 		defer dirCleanup()
 
-		reportItem := download.Media(media, downloadDir, time.Time{})
+		reportItem := download.Media(http.DefaultClient, download.Throttle{}, media, downloadDir, time.Time{})
 		require.IsType(t, &download.ReportErrorItem{}, reportItem)
 
 		errorItem := reportItem.(*download.ReportErrorItem)
@@ -138,6 +139,31 @@ func TestDownload(t *testing.T) {
 		assert.NotNil(t, errorItem.Err)
 	})
 
+	t.Run("reports denied requests as forbidden", func(t *testing.T) {
+		url, cleanup := testutils.HTTPServer(map[string]http.HandlerFunc{
+			"/media1": func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			},
+		})
+		defer cleanup()
+
+		media := patreon.Media{
+			ID:          "media1",
+			DownloadURL: url.JoinPath("media1").String(),
+			MimeType:    "image/jpeg",
+		}
+
+		downloadDir, dirCleanup, err := fsutils.TemporaryDirectory()
+		require.NoError(t, err)
+		defer dirCleanup()
+
+		reportItem := download.Media(http.DefaultClient, download.Throttle{}, media, downloadDir, time.Time{})
+		require.IsType(t, &download.ReportErrorItem{}, reportItem)
+
+		errorItem := reportItem.(*download.ReportErrorItem)
+		assert.ErrorIs(t, errorItem.Err, download.ErrForbidden)
+	})
+
 	t.Run("handles mime type errors", func(t *testing.T) {
 		media := patreon.Media{
 			ID:          "media1",
@@ -149,7 +175,7 @@ func TestDownload(t *testing.T) {
 		require.NoError(t, err)
 		defer dirCleanup()
 
-		reportItem := download.Media(media, downloadDir, time.Time{})
+		reportItem := download.Media(http.DefaultClient, download.Throttle{}, media, downloadDir, time.Time{})
 		require.IsType(t, &download.ReportErrorItem{}, reportItem)
 
 		errorItem := reportItem.(*download.ReportErrorItem)
@@ -13,6 +13,7 @@ func (m *reportItem) ReportItem() {}
 type ReportSuccessItem struct {
 	reportItem
 	Media patreon.Media
+	Bytes int64
 }
 
 type ReportSkippedItem struct {
@@ -27,9 +28,10 @@ type ReportErrorItem struct {
 	Err   error
 }
 
-func NewSuccessItem(media patreon.Media) ReportItem {
+func NewSuccessItem(media patreon.Media, bytes int64) ReportItem {
 	return &ReportSuccessItem{
 		Media: media,
+		Bytes: bytes,
 	}
 }
 
@@ -0,0 +1,95 @@
+package download
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/util/byteutils"
+)
+
+type RateWindow struct {
+	// Start and End are minutes since midnight. End before Start wraps around
+	// midnight.
+	Start int
+	End   int
+	Rate  int64
+}
+
+func (w RateWindow) contains(minute int) bool {
+	if w.Start <= w.End {
+		return minute >= w.Start && minute < w.End
+	}
+	return minute >= w.Start || minute < w.End
+}
+
+// RateSchedule applies the first window containing a time.
+type RateSchedule []RateWindow
+
+func ParseRateSchedule(entries []string) (RateSchedule, error) {
+	schedule := make(RateSchedule, 0, len(entries))
+	for _, entry := range entries {
+		window, err := parseRateWindow(entry)
+		if err != nil {
+			return nil, err
+		}
+		schedule = append(schedule, window)
+	}
+	return schedule, nil
+}
+
+func parseRateWindow(entry string) (RateWindow, error) {
+	span, rateValue, ok := strings.Cut(entry, "=")
+	if !ok {
+		return RateWindow{}, fmt.Errorf("invalid schedule entry %q, expected HH:MM-HH:MM=RATE", entry)
+	}
+	startValue, endValue, ok := strings.Cut(span, "-")
+	if !ok {
+		return RateWindow{}, fmt.Errorf("invalid schedule entry %q, expected HH:MM-HH:MM=RATE", entry)
+	}
+
+	start, err := parseTimeOfDay(startValue)
+	if err != nil {
+		return RateWindow{}, fmt.Errorf("invalid schedule entry %q: %w", entry, err)
+	}
+	end, err := parseTimeOfDay(endValue)
+	if err != nil {
+		return RateWindow{}, fmt.Errorf("invalid schedule entry %q: %w", entry, err)
+	}
+	if start == end {
+		return RateWindow{}, fmt.Errorf("invalid schedule entry %q: window is empty", entry)
+	}
+
+	var rate int64
+	if !strings.EqualFold(strings.TrimSpace(rateValue), "unlimited") {
+		rate, err = byteutils.ParseRate(rateValue)
+		if err != nil {
+			return RateWindow{}, fmt.Errorf("invalid schedule entry %q: %w", entry, err)
+		}
+	}
+
+	return RateWindow{Start: start, End: end, Rate: rate}, nil
+}
+
+// parseTimeOfDay accepts "24:00" as the end of the day.
+func parseTimeOfDay(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+func (s RateSchedule) RateAt(t time.Time, fallback int64) int64 {
+	minute := t.Hour()*60 + t.Minute()
+	for _, window := range s {
+		if window.contains(minute) {
+			return window.Rate
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,51 @@
+package download_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/download"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateSchedule(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	t.Run("parses windows", func(t *testing.T) {
+		schedule, err := download.ParseRateSchedule([]string{"01:00-07:30=unlimited", "18:00-24:00=1MB/s"})
+		require.NoError(t, err)
+		assert.Equal(t, download.RateSchedule{
+			{Start: 60, End: 450, Rate: 0},
+			{Start: 1080, End: 1440, Rate: 1000 * 1000},
+		}, schedule)
+	})
+
+	t.Run("returns the rate of the first matching window", func(t *testing.T) {
+		schedule, err := download.ParseRateSchedule([]string{"01:00-07:00=0", "06:00-08:00=2MB"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), schedule.RateAt(at(6, 30), 500))
+		assert.Equal(t, int64(2000*1000), schedule.RateAt(at(7, 0), 500))
+		assert.Equal(t, int64(500), schedule.RateAt(at(8, 0), 500))
+		assert.Equal(t, int64(500), schedule.RateAt(at(0, 59), 500))
+	})
+
+	t.Run("wraps windows around midnight", func(t *testing.T) {
+		schedule, err := download.ParseRateSchedule([]string{"22:00-06:00=unlimited"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), schedule.RateAt(at(23, 0), 500))
+		assert.Equal(t, int64(0), schedule.RateAt(at(5, 59), 500))
+		assert.Equal(t, int64(500), schedule.RateAt(at(6, 0), 500))
+		assert.Equal(t, int64(500), schedule.RateAt(at(21, 59), 500))
+	})
+
+	t.Run("rejects invalid entries", func(t *testing.T) {
+		for _, entry := range []string{"01:00-07:00", "01:00=1MB", "25:00-07:00=1MB", "01:00-07:00=fast", "03:00-03:00=1MB"} {
+			_, err := download.ParseRateSchedule([]string{entry})
+			assert.Error(t, err, entry)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package download
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// maxThrottledRead spreads slow rates evenly instead of in bursts.
+const maxThrottledRead = 32 * 1024
+
+// Limiter is a token bucket shared by concurrent downloads. A rate of 0 is
+// unlimited.
+type Limiter struct {
+	mutex  sync.Mutex
+	rate   int64
+	tokens float64
+	last   time.Time
+}
+
+func NewLimiter(rate int64) *Limiter {
+	return &Limiter{rate: rate, tokens: float64(rate), last: time.Now()}
+}
+
+func (l *Limiter) SetRate(rate int64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if rate == l.rate {
+		return
+	}
+	l.rate = rate
+	l.tokens = min(l.tokens, float64(rate))
+	l.last = time.Now()
+}
+
+func (l *Limiter) Rate() int64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.rate
+}
+
+// take lets the bucket go into debt, so concurrent readers are served in
+// order. It holds at most one second worth of bytes.
+func (l *Limiter) take(n int) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.rate <= 0 {
+		return 0
+	}
+	now := time.Now()
+	l.tokens = min(float64(l.rate), l.tokens+now.Sub(l.last).Seconds()*float64(l.rate))
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / float64(l.rate) * float64(time.Second))
+}
+
+func (l *Limiter) wait(n int) {
+	if delay := l.take(n); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// Throttle is unlimited if zero.
+type Throttle struct {
+	Global      *Limiter
+	PerDownload int64
+}
+
+func (t Throttle) reader(reader io.Reader) io.Reader {
+	var limiters []*Limiter
+	if t.Global != nil {
+		limiters = append(limiters, t.Global)
+	}
+	if t.PerDownload > 0 {
+		limiters = append(limiters, NewLimiter(t.PerDownload))
+	}
+	if len(limiters) == 0 {
+		return reader
+	}
+	return &throttledReader{reader: reader, limiters: limiters}
+}
+
+type throttledReader struct {
+	reader   io.Reader
+	limiters []*Limiter
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > maxThrottledRead {
+		p = p[:maxThrottledRead]
+	}
+	n, err := r.reader.Read(p)
+	for _, limiter := range r.limiters {
+		limiter.wait(n)
+	}
+	return n, err
+}
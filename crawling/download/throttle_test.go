@@ -0,0 +1,75 @@
+package download_test
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/download"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/util/fsutils"
+	"github.com/MatthiasHarzer/patreon-crawler/util/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottle(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 3000)
+	url, cleanup := testutils.HTTPServer(map[string]http.HandlerFunc{
+		"/media.jpg": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(content)
+		},
+	})
+	defer cleanup()
+
+	media := patreon.Media{
+		ID:          "media",
+		DownloadURL: url.String() + "media.jpg",
+		MimeType:    "image/jpeg",
+	}
+
+	measure := func(t *testing.T, throttle download.Throttle) time.Duration {
+		downloadDir, dirCleanup, err := fsutils.TemporaryDirectory()
+		require.NoError(t, err)
+		defer dirCleanup()
+
+		start := time.Now()
+		reportItem := download.Media(http.DefaultClient, throttle, media, downloadDir, time.Time{})
+		elapsed := time.Since(start)
+		require.IsType(t, &download.ReportSuccessItem{}, reportItem)
+
+		mediaFile, err := download.GetMediaFile(downloadDir, media)
+		require.NoError(t, err)
+		written, err := os.ReadFile(mediaFile)
+		require.NoError(t, err)
+		assert.Equal(t, content, written)
+		return elapsed
+	}
+
+	t.Run("limits the global rate", func(t *testing.T) {
+		// The first second worth of bytes is a burst, the rest takes 0.5s
+		elapsed := measure(t, download.Throttle{Global: download.NewLimiter(2000)})
+		assert.GreaterOrEqual(t, elapsed, 400*time.Millisecond)
+	})
+
+	t.Run("limits the rate per download", func(t *testing.T) {
+		elapsed := measure(t, download.Throttle{PerDownload: 2000})
+		assert.GreaterOrEqual(t, elapsed, 400*time.Millisecond)
+	})
+
+	t.Run("does not limit a zero rate", func(t *testing.T) {
+		elapsed := measure(t, download.Throttle{Global: download.NewLimiter(0)})
+		assert.Less(t, elapsed, 400*time.Millisecond)
+	})
+
+	t.Run("applies rate changes", func(t *testing.T) {
+		limiter := download.NewLimiter(0)
+		limiter.SetRate(2000)
+		assert.Equal(t, int64(2000), limiter.Rate())
+		elapsed := measure(t, download.Throttle{Global: limiter})
+		assert.GreaterOrEqual(t, elapsed, 400*time.Millisecond)
+	})
+}
@@ -0,0 +1,61 @@
+package crawling
+
+import (
+	"slices"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/queue"
+)
+
+const largeMediaSize = 20 * 1000 * 1000
+
+type PostMedia struct {
+	Post  patreon.Post
+	Media patreon.Media
+}
+
+func isAttachment(item PostMedia) bool {
+	return slices.ContainsFunc(item.Post.Attachments, func(m patreon.Media) bool { return m.ID == item.Media.ID })
+}
+
+// downloadPriority starts attachments, usually the originals, first.
+func downloadPriority(item PostMedia) queue.Priority {
+	if isAttachment(item) {
+		return queue.PriorityHigh
+	}
+	return queue.PriorityNormal
+}
+
+// orderDownloads interleaves large media with small media, so large downloads
+// do not occupy all workers at once.
+func orderDownloads(items []PostMedia) []PostMedia {
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b PostMedia) int {
+		return b.Post.PublishedAt.Compare(a.Post.PublishedAt)
+	})
+
+	var small, large []int
+	for index, item := range sorted {
+		if item.Media.SizeBytes >= largeMediaSize {
+			large = append(large, index)
+		} else {
+			small = append(small, index)
+		}
+	}
+
+	ordered := make([]PostMedia, 0, len(sorted))
+	previousLarge := false
+	for len(small) > 0 || len(large) > 0 {
+		// Take the newer of both, but never two large media in a row
+		takeLarge := len(large) > 0 && (len(small) == 0 || (!previousLarge && large[0] < small[0]))
+		if takeLarge {
+			ordered = append(ordered, sorted[large[0]])
+			large = large[1:]
+		} else {
+			ordered = append(ordered, sorted[small[0]])
+			small = small[1:]
+		}
+		previousLarge = takeLarge
+	}
+	return ordered
+}
@@ -1,7 +1,12 @@
 package crawling
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
 
 	"github.com/MatthiasHarzer/patreon-crawler/crawling/download"
 	"github.com/MatthiasHarzer/patreon-crawler/patreon"
@@ -16,14 +21,6 @@ const (
 	GroupingStrategyByPost GroupingStrategy = "by-post"
 )
 
-type MediaSelection string
-
-const (
-	MediaSelectionImages      MediaSelection = "images"
-	MediaSelectionAttachments MediaSelection = "attachments"
-	MediaSelectionAll         MediaSelection = "all"
-)
-
 func getDownloadDir(baseDownloadDir, postTitle string, groupingStrategy GroupingStrategy) (string, error) {
 	switch groupingStrategy {
 	case GroupingStrategyByPost:
@@ -35,39 +32,160 @@ func getDownloadDir(baseDownloadDir, postTitle string, groupingStrategy Grouping
 	}
 }
 
+// downloadAttempts includes the retry, which starts after the other downloads.
+const downloadAttempts = 2
+
+type DownloadResult struct {
+	Post    patreon.Post
+	Media   patreon.Media
+	Report  download.ReportItem
+	retried bool
+}
+
+// MediaRefresher returns the media with a freshly signed download URL.
+type MediaRefresher func(parentPost patreon.Post, media patreon.Media) (patreon.Media, error)
+
+type DownloadQueue = queue.Queue[DownloadResult]
+
+func NewDownloadQueue(concurrencyLimit int) (*DownloadQueue, error) {
+	return queue.New[DownloadResult](concurrencyLimit)
+}
+
 type Downloader struct {
 	baseDownloadDir  string
 	groupingStrategy GroupingStrategy
-	downloadQueue    *queue.Queue
+	client           *http.Client
+	throttle         download.Throttle
+	budget           *Budget
+	refresh          MediaRefresher
+	downloads        *queue.Batch[DownloadResult]
+	resultsOnce      sync.Once
+	results          chan queue.Result[DownloadResult]
 }
 
-func NewDownloader(baseDownloadDir string, concurrencyLimit int, groupingStrategy GroupingStrategy) (*Downloader, error) {
-	downloadQueue, err := queue.New(concurrencyLimit)
-	if err != nil {
-		return nil, err
-	}
+// NewDownloader may share the queue, client and throttle across creators.
+func NewDownloader(baseDownloadDir string, downloadQueue *DownloadQueue, client *http.Client, throttle download.Throttle, budget *Budget, groupingStrategy GroupingStrategy) *Downloader {
 	return &Downloader{
 		baseDownloadDir:  baseDownloadDir,
 		groupingStrategy: groupingStrategy,
-		downloadQueue:    downloadQueue,
-	}, nil
+		client:           client,
+		throttle:         throttle,
+		budget:           budget,
+		downloads:        downloadQueue.Batch(),
+	}
+}
+
+func (d *Downloader) SetRefresher(refresh MediaRefresher) {
+	d.refresh = refresh
+}
+
+func (d *Downloader) postDownloadDir(creatorVanityID string, parentPost patreon.Post) (string, error) {
+	creatorDownloadDir := fmt.Sprintf("%s/%s", d.baseDownloadDir, fsutils.SanitizeFilename(creatorVanityID))
+	return getDownloadDir(creatorDownloadDir, parentPost.Title, d.groupingStrategy)
+}
+
+func (d *Downloader) IsDownloaded(creatorVanityID string, parentPost patreon.Post, media patreon.Media) bool {
+	postDownloadDir, err := d.postDownloadDir(creatorVanityID, parentPost)
+	if err != nil {
+		return false
+	}
+	mediaFile, err := download.GetMediaFile(postDownloadDir, media)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(mediaFile)
+	return err == nil
 }
 
-func (d *Downloader) Enqueue(creatorVanityID string, parentPost patreon.Post, media patreon.Media, onDone func(item download.ReportItem)) {
-	d.downloadQueue.Enqueue(func() error {
-		creatorDownloadDir := fmt.Sprintf("%s/%s", d.baseDownloadDir, fsutils.SanitizeFilename(creatorVanityID))
-		postDownloadDir, err := getDownloadDir(creatorDownloadDir, parentPost.Title, d.groupingStrategy)
-		if err != nil {
-			return fmt.Errorf("failed to get download directory: %w", err)
+func (d *Downloader) Enqueue(creatorVanityID string, parentPost patreon.Post, media patreon.Media, priority queue.Priority) {
+	d.submit(creatorVanityID, parentPost, media, priority, 1)
+}
+
+func (d *Downloader) EnqueueAll(creatorVanityID string, items []PostMedia) {
+	for _, item := range orderDownloads(items) {
+		d.Enqueue(creatorVanityID, item.Post, item.Media, downloadPriority(item))
+	}
+}
+
+func (d *Downloader) submit(creatorVanityID string, parentPost patreon.Post, media patreon.Media, priority queue.Priority, attempt int) {
+	d.downloads.Submit(func() (DownloadResult, error) {
+		return d.download(creatorVanityID, parentPost, media, attempt)
+	}, priority)
+}
+
+func (d *Downloader) download(creatorVanityID string, parentPost patreon.Post, media patreon.Media, attempt int) (DownloadResult, error) {
+	postDownloadDir, err := d.postDownloadDir(creatorVanityID, parentPost)
+	if err != nil {
+		return DownloadResult{}, fmt.Errorf("failed to get download directory: %w", err)
+	}
+
+	reportItem := download.Media(d.client, d.throttle, media, postDownloadDir, parentPost.PublishedAt)
+	switch item := reportItem.(type) {
+	case *download.ReportSuccessItem:
+		if d.budget != nil && media.SizeBytes == 0 {
+			d.budget.AddDownloadedBytes(item.Bytes)
+		}
+	case *download.ReportErrorItem:
+		if attempt < downloadAttempts {
+			retryMedia, ok := d.retryMedia(parentPost, media, item.Err)
+			if ok {
+				slog.Debug("media download failed, retrying", "creator", creatorVanityID, "post", parentPost.ID, "media", media.ID, "attempt", attempt, "error", item.Err)
+				d.submit(creatorVanityID, parentPost, retryMedia, queue.PriorityLow, attempt+1)
+				return DownloadResult{Post: parentPost, Media: media, Report: reportItem, retried: true}, nil
+			}
 		}
+		slog.Debug("media download failed", "creator", creatorVanityID, "post", parentPost.ID, "media", media.ID, "error", item.Err)
+	case *download.ReportSkippedItem:
+		slog.Debug("media download skipped", "creator", creatorVanityID, "post", parentPost.ID, "media", media.ID, "reason", item.Reason)
+	}
 
-		reportItem := download.Media(media, postDownloadDir, parentPost.PublishedAt)
+	return DownloadResult{Post: parentPost, Media: media, Report: reportItem}, nil
+}
 
-		onDone(reportItem)
-		return nil
+// retryMedia only retries denied downloads with a fresh URL, as the signed URL
+// stays denied.
+func (d *Downloader) retryMedia(parentPost patreon.Post, media patreon.Media, err error) (patreon.Media, bool) {
+	if !errors.Is(err, download.ErrForbidden) {
+		return media, true
+	}
+	if d.refresh == nil {
+		return patreon.Media{}, false
+	}
+	refreshed, err := d.refresh(parentPost, media)
+	if err != nil {
+		slog.Debug("failed to refresh denied media", "post", parentPost.ID, "media", media.ID, "error", err)
+		return patreon.Media{}, false
+	}
+	return refreshed, true
+}
+
+func (d *Downloader) Close() {
+	d.downloads.Close()
+}
+
+// Results with an error are downloads that failed to run at all.
+func (d *Downloader) Results() <-chan queue.Result[DownloadResult] {
+	d.resultsOnce.Do(func() {
+		d.results = make(chan queue.Result[DownloadResult])
+		go func() {
+			defer close(d.results)
+			for result := range d.downloads.Results() {
+				// Only the last attempt of a download is reported
+				if result.Err == nil && result.Value.retried {
+					continue
+				}
+				d.results <- result
+			}
+		}()
 	})
+	return d.results
+}
+
+func (d *Downloader) Wait() error {
+	slog.Debug("waiting for downloads", "dir", d.baseDownloadDir, "grouping", d.groupingStrategy)
+	return d.downloads.Wait()
 }
 
-func (d *Downloader) ProcessAll() error {
-	return d.downloadQueue.ProcessAll()
+func (d *Downloader) Stop() {
+	d.downloads.Stop()
 }
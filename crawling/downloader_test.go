@@ -0,0 +1,210 @@
+package crawling_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/crawling"
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/download"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/queue"
+	"github.com/MatthiasHarzer/patreon-crawler/util/fsutils"
+	"github.com/MatthiasHarzer/patreon-crawler/util/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloader(t *testing.T) {
+	t.Run("finishes reserved posts of unknown size beyond the byte budget", func(t *testing.T) {
+		url, cleanup := testutils.HTTPServer(map[string]http.HandlerFunc{
+			"/": func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(bytes.Repeat([]byte("a"), 600))
+			},
+		})
+		defer cleanup()
+
+		downloadDir, dirCleanup, err := fsutils.TemporaryDirectory()
+		require.NoError(t, err)
+		defer dirCleanup()
+
+		downloadQueue, err := crawling.NewDownloadQueue(1)
+		require.NoError(t, err)
+		downloadQueue.Start()
+		defer downloadQueue.Close()
+
+		budget := crawling.NewBudget(0, 0, 1000)
+		downloader := crawling.NewDownloader(downloadDir, downloadQueue, http.DefaultClient, download.Throttle{}, budget, crawling.GroupingStrategyNone)
+
+		post := patreon.Post{ID: "post", Title: "Post"}
+		var media []patreon.Media
+		for i := 0; i < 5; i++ {
+			media = append(media, patreon.Media{
+				ID:          fmt.Sprintf("media%d", i),
+				DownloadURL: fmt.Sprintf("%smedia%d.jpg", url.String(), i),
+				MimeType:    "image/jpeg",
+			})
+		}
+		require.True(t, budget.Reserve(media))
+		for _, m := range media {
+			downloader.Enqueue("creator", post, m, queue.PriorityNormal)
+		}
+		downloader.Close()
+
+		require.NoError(t, downloader.Wait())
+		entries, err := os.ReadDir(downloadDir + "/creator")
+		require.NoError(t, err)
+		assert.Len(t, entries, 5)
+		assert.True(t, budget.Exhausted())
+		assert.False(t, budget.Reserve(media))
+	})
+	t.Run("orders downloads by priority, age and size", func(t *testing.T) {
+		var mutex sync.Mutex
+		var requested []string
+		url, cleanup := testutils.HTTPServer(map[string]http.HandlerFunc{
+			"/": func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+				requested = append(requested, strings.TrimPrefix(r.URL.Path, "/"))
+				mutex.Unlock()
+				_, _ = w.Write([]byte("content"))
+			},
+		})
+		defer cleanup()
+
+		downloadDir, dirCleanup, err := fsutils.TemporaryDirectory()
+		require.NoError(t, err)
+		defer dirCleanup()
+
+		newMedia := func(id string, size int64) patreon.Media {
+			return patreon.Media{ID: id, DownloadURL: url.String() + id, MimeType: "image/jpeg", SizeBytes: size}
+		}
+		large1 := newMedia("large1", 50*1000*1000)
+		large2 := newMedia("large2", 50*1000*1000)
+		small := newMedia("small", 1000)
+		old := newMedia("old", 1000)
+		attachment := newMedia("attachment", 1000)
+		newPost := patreon.Post{ID: "new", PublishedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Media: []patreon.Media{large1, large2, small}}
+		oldPost := patreon.Post{ID: "old", PublishedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Media: []patreon.Media{old}, Attachments: []patreon.Media{attachment}}
+
+		// The queue starts after all downloads are enqueued, so their order is
+		// not affected by timing
+		downloadQueue, err := crawling.NewDownloadQueue(1)
+		require.NoError(t, err)
+		defer downloadQueue.Close()
+		downloader := crawling.NewDownloader(downloadDir, downloadQueue, http.DefaultClient, download.Throttle{}, nil, crawling.GroupingStrategyNone)
+		downloader.EnqueueAll("creator", []crawling.PostMedia{
+			{Post: oldPost, Media: old},
+			{Post: oldPost, Media: attachment},
+			{Post: newPost, Media: large1},
+			{Post: newPost, Media: large2},
+			{Post: newPost, Media: small},
+		})
+		downloader.Close()
+		downloadQueue.Start()
+
+		require.NoError(t, downloader.Wait())
+		assert.Equal(t, []string{"attachment", "large1", "small", "large2", "old"}, requested)
+	})
+
+	t.Run("retries failed downloads after the others", func(t *testing.T) {
+		var mutex sync.Mutex
+		var requested []string
+		url, cleanup := testutils.HTTPServer(map[string]http.HandlerFunc{
+			"/": func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				id := strings.TrimPrefix(r.URL.Path, "/")
+				failed := !slices.Contains(requested, id) && id == "flaky"
+				requested = append(requested, id)
+				if failed {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				_, _ = w.Write([]byte("content"))
+			},
+		})
+		defer cleanup()
+
+		downloadDir, dirCleanup, err := fsutils.TemporaryDirectory()
+		require.NoError(t, err)
+		defer dirCleanup()
+
+		downloadQueue, err := crawling.NewDownloadQueue(1)
+		require.NoError(t, err)
+		downloadQueue.Start()
+		defer downloadQueue.Close()
+		downloader := crawling.NewDownloader(downloadDir, downloadQueue, http.DefaultClient, download.Throttle{}, nil, crawling.GroupingStrategyNone)
+
+		post := patreon.Post{ID: "post"}
+		for _, id := range []string{"flaky", "other"} {
+			downloader.Enqueue("creator", post, patreon.Media{ID: id, DownloadURL: url.String() + id, MimeType: "image/jpeg"}, queue.PriorityNormal)
+		}
+		downloader.Close()
+
+		var reports []download.ReportItem
+		for result := range downloader.Results() {
+			require.NoError(t, result.Err)
+			reports = append(reports, result.Value.Report)
+		}
+		require.NoError(t, downloader.Wait())
+
+		assert.Equal(t, []string{"flaky", "other", "flaky"}, requested)
+		require.Len(t, reports, 2)
+		for _, report := range reports {
+			assert.IsType(t, &download.ReportSuccessItem{}, report)
+		}
+	})
+	t.Run("retries denied downloads with a refreshed URL", func(t *testing.T) {
+		var mutex sync.Mutex
+		var requested []string
+		url, cleanup := testutils.HTTPServer(map[string]http.HandlerFunc{
+			"/": func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				id := strings.TrimPrefix(r.URL.Path, "/")
+				requested = append(requested, id)
+				if id == "stale" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				_, _ = w.Write([]byte("content"))
+			},
+		})
+		defer cleanup()
+
+		downloadDir, dirCleanup, err := fsutils.TemporaryDirectory()
+		require.NoError(t, err)
+		defer dirCleanup()
+
+		downloadQueue, err := crawling.NewDownloadQueue(1)
+		require.NoError(t, err)
+		downloadQueue.Start()
+		defer downloadQueue.Close()
+		downloader := crawling.NewDownloader(downloadDir, downloadQueue, http.DefaultClient, download.Throttle{}, nil, crawling.GroupingStrategyNone)
+		downloader.SetRefresher(func(_ patreon.Post, media patreon.Media) (patreon.Media, error) {
+			media.DownloadURL = url.String() + "fresh"
+			return media, nil
+		})
+
+		downloader.Enqueue("creator", patreon.Post{ID: "post"}, patreon.Media{ID: "media", DownloadURL: url.String() + "stale", MimeType: "image/jpeg"}, queue.PriorityNormal)
+		downloader.Close()
+
+		var reports []download.ReportItem
+		for result := range downloader.Results() {
+			require.NoError(t, result.Err)
+			reports = append(reports, result.Value.Report)
+		}
+		require.NoError(t, downloader.Wait())
+
+		assert.Equal(t, []string{"stale", "fresh"}, requested)
+		require.Len(t, reports, 1)
+		assert.IsType(t, &download.ReportSuccessItem{}, reports[0])
+	})
+}
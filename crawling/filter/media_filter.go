@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+)
+
+// MediaFilter does not exclude media of unknown size or dimensions by the
+// respective bounds.
+type MediaFilter struct {
+	MimeTypes         []string
+	MinSizeBytes      int64
+	MaxSizeBytes      int64
+	MinWidth          int
+	MinHeight         int
+	Extensions        []string
+	ExcludeExtensions []string
+}
+
+// MediaExtension falls back to the mime type if the file name has no extension.
+func MediaExtension(media patreon.Media) string {
+	extension := strings.TrimPrefix(filepath.Ext(media.FileName), ".")
+	if extension == "" {
+		_, subtype, found := strings.Cut(media.MimeType, "/")
+		if found {
+			extension = subtype
+		}
+	}
+	return strings.ToLower(extension)
+}
+
+func normalizeExtension(extension string) string {
+	return strings.ToLower(strings.TrimPrefix(extension, "."))
+}
+
+func containsExtension(extensions []string, extension string) bool {
+	for _, e := range extensions {
+		if normalizeExtension(e) == extension {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMimeType(patterns []string, mimeType string) bool {
+	for _, pattern := range patterns {
+		matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(mimeType))
+		if err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (f MediaFilter) Matches(media patreon.Media) bool {
+	if len(f.MimeTypes) > 0 && !matchesMimeType(f.MimeTypes, media.MimeType) {
+		return false
+	}
+
+	if media.SizeBytes > 0 {
+		if media.SizeBytes < f.MinSizeBytes {
+			return false
+		}
+		if f.MaxSizeBytes > 0 && media.SizeBytes > f.MaxSizeBytes {
+			return false
+		}
+	}
+
+	if media.Width > 0 && media.Width < f.MinWidth {
+		return false
+	}
+	if media.Height > 0 && media.Height < f.MinHeight {
+		return false
+	}
+
+	extension := MediaExtension(media)
+	if len(f.Extensions) > 0 && !containsExtension(f.Extensions, extension) {
+		return false
+	}
+	if containsExtension(f.ExcludeExtensions, extension) {
+		return false
+	}
+
+	return true
+}
+
+func (f MediaFilter) Apply(media []patreon.Media) []patreon.Media {
+	var matching []patreon.Media
+	for _, m := range media {
+		if f.Matches(m) {
+			matching = append(matching, m)
+		}
+	}
+	return matching
+}
+
+func ValidateMimeTypes(patterns []string) error {
+	for _, pattern := range patterns {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("invalid mime type pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
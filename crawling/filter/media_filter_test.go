@@ -0,0 +1,88 @@
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/filter"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMediaFilter(t *testing.T) {
+	image := patreon.Media{
+		ID:        "image-id",
+		MimeType:  "image/png",
+		Width:     1920,
+		Height:    1080,
+		SizeBytes: 2_000_000,
+	}
+	bundle := patreon.Media{
+		ID:        "bundle-id",
+		FileName:  "Artwork.PSD",
+		MimeType:  "image/vnd.adobe.photoshop",
+		SizeBytes: 900_000_000,
+	}
+	unknownSize := patreon.Media{
+		ID:       "unknown-id",
+		FileName: "pack.zip",
+		MimeType: "application/zip",
+	}
+
+	t.Run("zero value matches all media", func(t *testing.T) {
+		media := []patreon.Media{image, bundle, unknownSize}
+		assert.Equal(t, media, filter.MediaFilter{}.Apply(media))
+	})
+
+	t.Run("mime type globs", func(t *testing.T) {
+		f := filter.MediaFilter{MimeTypes: []string{"image/*"}}
+		assert.True(t, f.Matches(image))
+		assert.True(t, f.Matches(bundle))
+		assert.False(t, f.Matches(unknownSize))
+
+		f = filter.MediaFilter{MimeTypes: []string{"application/zip"}}
+		assert.False(t, f.Matches(image))
+		assert.True(t, f.Matches(unknownSize))
+	})
+
+	t.Run("size bounds ignore unknown sizes", func(t *testing.T) {
+		f := filter.MediaFilter{MinSizeBytes: 100_000, MaxSizeBytes: 100_000_000}
+		assert.True(t, f.Matches(image))
+		assert.False(t, f.Matches(bundle))
+		assert.True(t, f.Matches(unknownSize))
+
+		f = filter.MediaFilter{MinSizeBytes: 5_000_000}
+		assert.False(t, f.Matches(image))
+	})
+
+	t.Run("minimum dimensions ignore unknown dimensions", func(t *testing.T) {
+		f := filter.MediaFilter{MinWidth: 800, MinHeight: 600}
+		assert.True(t, f.Matches(image))
+		assert.True(t, f.Matches(bundle))
+
+		f = filter.MediaFilter{MinHeight: 1440}
+		assert.False(t, f.Matches(image))
+	})
+
+	t.Run("extension allow and deny lists", func(t *testing.T) {
+		f := filter.MediaFilter{Extensions: []string{"png", ".zip"}}
+		assert.True(t, f.Matches(image))
+		assert.False(t, f.Matches(bundle))
+		assert.True(t, f.Matches(unknownSize))
+
+		f = filter.MediaFilter{ExcludeExtensions: []string{"psd"}}
+		assert.True(t, f.Matches(image))
+		assert.False(t, f.Matches(bundle))
+	})
+}
+
+func TestMediaExtension(t *testing.T) {
+	assert.Equal(t, "psd", filter.MediaExtension(patreon.Media{FileName: "Artwork.PSD", MimeType: "image/vnd.adobe.photoshop"}))
+	assert.Equal(t, "png", filter.MediaExtension(patreon.Media{MimeType: "image/png"}))
+	assert.Equal(t, "", filter.MediaExtension(patreon.Media{}))
+}
+
+func TestValidateMimeTypes(t *testing.T) {
+	assert.NoError(t, filter.ValidateMimeTypes([]string{"image/*", "application/zip"}))
+	assert.Error(t, filter.ValidateMimeTypes([]string{"image/["}))
+}
@@ -0,0 +1,119 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+)
+
+// PostFilter matches every post if zero.
+type PostFilter struct {
+	Since time.Time
+	// Until is exclusive.
+	Until          time.Time
+	AccessibleOnly bool
+	HasAttachments bool
+	TitleMatch     *regexp.Regexp
+	ExcludeTitle   *regexp.Regexp
+	PostTypes      []string
+	Tags           []string
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+}
+
+func (f PostFilter) matchesTags(post patreon.Post) bool {
+	if len(f.Tags) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(post.Tags, func(tag string) bool {
+		return containsFold(f.Tags, tag)
+	})
+}
+
+func (f PostFilter) Matches(post patreon.Post) bool {
+	if !f.Since.IsZero() && post.PublishedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !post.PublishedAt.Before(f.Until) {
+		return false
+	}
+	if f.AccessibleOnly && !post.CurrentUserCanView {
+		return false
+	}
+	if f.HasAttachments && len(post.Attachments) == 0 {
+		return false
+	}
+	if f.TitleMatch != nil && !f.TitleMatch.MatchString(post.Title) {
+		return false
+	}
+	if f.ExcludeTitle != nil && f.ExcludeTitle.MatchString(post.Title) {
+		return false
+	}
+	if len(f.PostTypes) > 0 && !containsFold(f.PostTypes, post.PostType) {
+		return false
+	}
+	return f.matchesTags(post)
+}
+
+// IsPastRange reports whether no later post can match, as posts are fetched
+// newest first.
+func (f PostFilter) IsPastRange(post patreon.Post) bool {
+	return !f.Since.IsZero() && post.PublishedAt.Before(f.Since)
+}
+
+func parseDate(value string) (time.Time, bool, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, false, nil
+	}
+	t, err = time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date %q. Must be in the format YYYY-MM-DD or RFC 3339", value)
+}
+
+// ParseSince treats dates without a time as the start of that day in local
+// time.
+func ParseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, _, err := parseDate(value)
+	return t, err
+}
+
+// ParseUntil returns the exclusive end of dates without a time in local
+// time.
+func ParseUntil(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, dateOnly, err := parseDate(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if dateOnly {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func ParseRegexp(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+	}
+	return compiled, nil
+}
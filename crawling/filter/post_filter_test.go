@@ -0,0 +1,139 @@
+package filter_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/crawling/filter"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostFilter(t *testing.T) {
+	post := patreon.Post{
+		ID:                 "post-id",
+		PublishedAt:        time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC),
+		CurrentUserCanView: false,
+	}
+
+	t.Run("zero value matches every post", func(t *testing.T) {
+		assert.True(t, filter.PostFilter{}.Matches(post))
+	})
+
+	t.Run("date range", func(t *testing.T) {
+		inRange := filter.PostFilter{
+			Since: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			Until: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		}
+		assert.True(t, inRange.Matches(post))
+		assert.False(t, inRange.IsPastRange(post))
+
+		tooOld := filter.PostFilter{Since: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)}
+		assert.False(t, tooOld.Matches(post))
+		assert.True(t, tooOld.IsPastRange(post))
+
+		tooNew := filter.PostFilter{Until: post.PublishedAt}
+		assert.False(t, tooNew.Matches(post))
+		assert.False(t, tooNew.IsPastRange(post))
+	})
+
+	t.Run("accessible only", func(t *testing.T) {
+		f := filter.PostFilter{AccessibleOnly: true}
+		assert.False(t, f.Matches(post))
+
+		accessiblePost := post
+		accessiblePost.CurrentUserCanView = true
+		assert.True(t, f.Matches(accessiblePost))
+	})
+
+	t.Run("has attachments", func(t *testing.T) {
+		f := filter.PostFilter{HasAttachments: true}
+		assert.False(t, f.Matches(post))
+
+		postWithAttachments := post
+		postWithAttachments.Attachments = []patreon.Media{{ID: "attachment-id"}}
+		assert.True(t, f.Matches(postWithAttachments))
+	})
+}
+
+func TestPostFilterMetadata(t *testing.T) {
+	post := patreon.Post{
+		ID:       "post-id",
+		Title:    "Weekly Sketch Dump #12",
+		PostType: "image_file",
+		Tags:     []string{"Sketch", "WIP"},
+	}
+
+	t.Run("title match", func(t *testing.T) {
+		assert.True(t, filter.PostFilter{TitleMatch: regexp.MustCompile(`(?i)sketch`)}.Matches(post))
+		assert.False(t, filter.PostFilter{TitleMatch: regexp.MustCompile(`^Poll`)}.Matches(post))
+	})
+
+	t.Run("exclude title", func(t *testing.T) {
+		assert.False(t, filter.PostFilter{ExcludeTitle: regexp.MustCompile(`Dump`)}.Matches(post))
+		assert.True(t, filter.PostFilter{ExcludeTitle: regexp.MustCompile(`^Poll`)}.Matches(post))
+	})
+
+	t.Run("post types", func(t *testing.T) {
+		assert.True(t, filter.PostFilter{PostTypes: []string{"text_only", "IMAGE_FILE"}}.Matches(post))
+		assert.False(t, filter.PostFilter{PostTypes: []string{"video_embed"}}.Matches(post))
+	})
+
+	t.Run("tags match any tag case-insensitively", func(t *testing.T) {
+		assert.True(t, filter.PostFilter{Tags: []string{"final", "wip"}}.Matches(post))
+		assert.False(t, filter.PostFilter{Tags: []string{"final"}}.Matches(post))
+
+		untagged := post
+		untagged.Tags = nil
+		assert.False(t, filter.PostFilter{Tags: []string{"sketch"}}.Matches(untagged))
+	})
+}
+
+func TestParseRegexp(t *testing.T) {
+	t.Run("empty pattern is unset", func(t *testing.T) {
+		compiled, err := filter.ParseRegexp("")
+		require.NoError(t, err)
+		assert.Nil(t, compiled)
+	})
+
+	t.Run("fails for invalid patterns", func(t *testing.T) {
+		_, err := filter.ParseRegexp("(")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseDates(t *testing.T) {
+	t.Run("empty values are unset", func(t *testing.T) {
+		since, err := filter.ParseSince("")
+		require.NoError(t, err)
+		assert.True(t, since.IsZero())
+
+		until, err := filter.ParseUntil("")
+		require.NoError(t, err)
+		assert.True(t, until.IsZero())
+	})
+
+	t.Run("dates include the entire day", func(t *testing.T) {
+		since, err := filter.ParseSince("2025-03-15")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.Local), since)
+
+		until, err := filter.ParseUntil("2025-03-15")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 3, 16, 0, 0, 0, 0, time.Local), until)
+	})
+
+	t.Run("parses RFC 3339 timestamps", func(t *testing.T) {
+		until, err := filter.ParseUntil("2025-03-15T12:30:00Z")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 3, 15, 12, 30, 0, 0, time.UTC), until)
+	})
+
+	t.Run("fails for invalid dates", func(t *testing.T) {
+		_, err := filter.ParseSince("15.03.2025")
+		assert.Error(t, err)
+	})
+}
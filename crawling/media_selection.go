@@ -0,0 +1,42 @@
+package crawling
+
+import (
+	"slices"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+)
+
+type MediaSelection string
+
+const (
+	MediaSelectionImages      MediaSelection = "images"
+	MediaSelectionAttachments MediaSelection = "attachments"
+	MediaSelectionAll         MediaSelection = "all"
+)
+
+func SelectMedia(post patreon.Post, mediaSelection MediaSelection) []patreon.Media {
+	switch mediaSelection {
+	case MediaSelectionAttachments:
+		return post.Attachments
+	case MediaSelectionAll:
+		return dedupeMediaByID(slices.Concat(post.Media, post.Attachments))
+	default:
+		return post.Media
+	}
+}
+
+// dedupeMediaByID removes media sharing an ID, keeping the first occurrence. A
+// post can reference the same media in both its Media and Attachments, which
+// would otherwise cause it to be downloaded twice under "all".
+func dedupeMediaByID(media []patreon.Media) []patreon.Media {
+	seen := make(map[string]struct{}, len(media))
+	result := make([]patreon.Media, 0, len(media))
+	for _, m := range media {
+		if _, ok := seen[m.ID]; ok {
+			continue
+		}
+		seen[m.ID] = struct{}{}
+		result = append(result, m)
+	}
+	return result
+}
@@ -0,0 +1,176 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// PassphraseFunc is called with confirm set when the passphrase is chosen.
+type PassphraseFunc func(confirm bool) ([]byte, error)
+
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted cookie file")
+
+const (
+	encryptedFileVersion = 1
+	saltSize             = 16
+	keySize              = 32
+	maxKDFMemoryKiB      = 4 * 1024 * 1024
+)
+
+// kdfParams are stored with each file, so they can be raised later without
+// breaking existing files.
+type kdfParams struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+var defaultKDFParams = kdfParams{Time: 1, Memory: 64 * 1024, Threads: 4}
+
+func (p kdfParams) validate() error {
+	if p.Time < 1 || p.Threads < 1 || p.Memory > maxKDFMemoryKiB {
+		return fmt.Errorf("invalid key derivation parameters")
+	}
+	return nil
+}
+
+type encryptedFile struct {
+	Version    int       `json:"version"`
+	KDF        kdfParams `json:"kdf"`
+	Salt       []byte    `json:"salt"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+type encryptedStore struct {
+	path       string
+	passphrase PassphraseFunc
+
+	mutex            sync.Mutex
+	cachedPassphrase []byte
+}
+
+// NewEncryptedStore requests the passphrase at most once.
+func NewEncryptedStore(path string, passphrase PassphraseFunc) Store {
+	return &encryptedStore{path: path, passphrase: passphrase}
+}
+
+func (s *encryptedStore) getPassphrase(confirm bool) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.cachedPassphrase != nil {
+		return s.cachedPassphrase, nil
+	}
+	passphrase, err := s.passphrase(confirm)
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+	s.cachedPassphrase = passphrase
+	return passphrase, nil
+}
+
+func (s *encryptedStore) forgetPassphrase() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cachedPassphrase = nil
+}
+
+func newGCM(passphrase, salt []byte, params kdfParams) (cipher.AEAD, error) {
+	key := argon2.IDKey(passphrase, salt, params.Time, params.Memory, params.Threads, keySize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *encryptedStore) Load() (string, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var file encryptedFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	if file.Version != encryptedFileVersion {
+		return "", fmt.Errorf("unsupported cookie file version %d", file.Version)
+	}
+	err = file.KDF.validate()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+
+	passphrase, err := s.getPassphrase(false)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(passphrase, file.Salt, file.KDF)
+	if err != nil {
+		return "", err
+	}
+	if len(file.Nonce) != gcm.NonceSize() {
+		return "", ErrWrongPassphrase
+	}
+	cookie, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		s.forgetPassphrase()
+		return "", ErrWrongPassphrase
+	}
+	return string(cookie), nil
+}
+
+func (s *encryptedStore) Save(cookie string) error {
+	_, statErr := os.Stat(s.path)
+	passphrase, err := s.getPassphrase(errors.Is(statErr, os.ErrNotExist))
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltSize)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(passphrase, salt, defaultKDFParams)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(encryptedFile{
+		Version:    encryptedFileVersion,
+		KDF:        defaultKDFParams,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, []byte(cookie), nil),
+	})
+	if err != nil {
+		return err
+	}
+	return writeFile(s.path, data)
+}
+
+func (s *encryptedStore) Delete() error {
+	return deleteFile(s.path)
+}
@@ -0,0 +1,90 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var ErrNotFound = errors.New("no cookie stored")
+
+type Store interface {
+	Load() (string, error)
+	Save(cookie string) error
+	Delete() error
+}
+
+type Backend string
+
+const (
+	BackendPlain     Backend = "plain"
+	BackendEncrypted Backend = "encrypted"
+)
+
+const (
+	plainFileName     = "cookie"
+	encryptedFileName = "cookie.enc"
+)
+
+func ValidateBackend(backend Backend) error {
+	if backend != BackendPlain && backend != BackendEncrypted {
+		return fmt.Errorf("invalid credential store %q. Must be one of: %s, %s", backend, BackendPlain, BackendEncrypted)
+	}
+	return nil
+}
+
+// NewStore only calls passphrase for the encrypted backend.
+func NewStore(backend Backend, dir string, passphrase PassphraseFunc) (Store, error) {
+	switch backend {
+	case BackendPlain:
+		return NewPlainStore(filepath.Join(dir, plainFileName)), nil
+	case BackendEncrypted:
+		return NewEncryptedStore(filepath.Join(dir, encryptedFileName), passphrase), nil
+	default:
+		return nil, ValidateBackend(backend)
+	}
+}
+
+func writeFile(path string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func deleteFile(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+type plainStore struct {
+	path string
+}
+
+func NewPlainStore(path string) Store {
+	return &plainStore{path: path}
+}
+
+func (s *plainStore) Load() (string, error) {
+	cookie, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(cookie), nil
+}
+
+func (s *plainStore) Save(cookie string) error {
+	return writeFile(s.path, []byte(cookie))
+}
+
+func (s *plainStore) Delete() error {
+	return deleteFile(s.path)
+}
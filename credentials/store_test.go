@@ -0,0 +1,120 @@
+package credentials_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MatthiasHarzer/patreon-crawler/credentials"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staticPassphrase(passphrase string) credentials.PassphraseFunc {
+	return func(_ bool) ([]byte, error) {
+		return []byte(passphrase), nil
+	}
+}
+
+func TestStores(t *testing.T) {
+	backends := []credentials.Backend{credentials.BackendPlain, credentials.BackendEncrypted}
+
+	for _, backend := range backends {
+		t.Run(string(backend), func(t *testing.T) {
+			t.Run("saves, loads and deletes the cookie", func(t *testing.T) {
+				store, err := credentials.NewStore(backend, t.TempDir(), staticPassphrase("secret"))
+				require.NoError(t, err)
+
+				_, err = store.Load()
+				assert.ErrorIs(t, err, credentials.ErrNotFound)
+
+				require.NoError(t, store.Save("session_id=abc"))
+				cookie, err := store.Load()
+				require.NoError(t, err)
+				assert.Equal(t, "session_id=abc", cookie)
+
+				require.NoError(t, store.Delete())
+				_, err = store.Load()
+				assert.ErrorIs(t, err, credentials.ErrNotFound)
+				assert.ErrorIs(t, store.Delete(), credentials.ErrNotFound)
+			})
+		})
+	}
+
+	t.Run("rejects unknown backends", func(t *testing.T) {
+		_, err := credentials.NewStore("keyring", t.TempDir(), nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestEncryptedStore(t *testing.T) {
+	t.Run("does not write the cookie in plain text", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cookie.enc")
+		store := credentials.NewEncryptedStore(path, staticPassphrase("secret"))
+		require.NoError(t, store.Save("session_id=abc"))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "session_id")
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("fails with the wrong passphrase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cookie.enc")
+		require.NoError(t, credentials.NewEncryptedStore(path, staticPassphrase("secret")).Save("session_id=abc"))
+
+		_, err := credentials.NewEncryptedStore(path, staticPassphrase("wrong")).Load()
+		assert.ErrorIs(t, err, credentials.ErrWrongPassphrase)
+	})
+
+	t.Run("requests the passphrase again after a wrong one", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cookie.enc")
+		require.NoError(t, credentials.NewEncryptedStore(path, staticPassphrase("secret")).Save("session_id=abc"))
+
+		passphrases := []string{"wrong", "secret"}
+		store := credentials.NewEncryptedStore(path, func(_ bool) ([]byte, error) {
+			passphrase := passphrases[0]
+			passphrases = passphrases[1:]
+			return []byte(passphrase), nil
+		})
+		_, err := store.Load()
+		assert.ErrorIs(t, err, credentials.ErrWrongPassphrase)
+		cookie, err := store.Load()
+		require.NoError(t, err)
+		assert.Equal(t, "session_id=abc", cookie)
+	})
+
+	t.Run("rejects invalid key derivation parameters", func(t *testing.T) {
+		for name, kdf := range map[string]string{
+			"no rounds":   `{"time":0,"memory":65536,"threads":4}`,
+			"no threads":  `{"time":1,"memory":65536,"threads":0}`,
+			"huge memory": `{"time":1,"memory":4294967295,"threads":4}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "cookie.enc")
+				data := `{"version":1,"kdf":` + kdf + `,"salt":"AAAAAAAAAAAAAAAAAAAAAA==","nonce":"AAAAAAAAAAAAAAAA","ciphertext":"AAAA"}`
+				require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+				_, err := credentials.NewEncryptedStore(path, staticPassphrase("secret")).Load()
+				assert.ErrorContains(t, err, "invalid key derivation parameters")
+			})
+		}
+	})
+
+	t.Run("requests the passphrase once and confirms new passphrases", func(t *testing.T) {
+		var calls []bool
+		store := credentials.NewEncryptedStore(filepath.Join(t.TempDir(), "cookie.enc"), func(confirm bool) ([]byte, error) {
+			calls = append(calls, confirm)
+			return []byte("secret"), nil
+		})
+
+		require.NoError(t, store.Save("session_id=abc"))
+		_, err := store.Load()
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true}, calls)
+	})
+}
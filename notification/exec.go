@@ -0,0 +1,73 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// execTimeout keeps a hung command from blocking the crawl.
+const execTimeout = 10 * time.Second
+
+type execNotifier struct {
+	command string
+}
+
+func NewExecNotifier(command string) Notifier {
+	return &execNotifier{command: command}
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+func eventEnv(event Event) []string {
+	env := []string{
+		"PATREON_EVENT=" + string(event.Type),
+		"PATREON_CREATOR=" + event.Creator,
+	}
+	if event.Post != nil {
+		env = append(env,
+			"PATREON_POST_ID="+event.Post.ID,
+			"PATREON_POST_TITLE="+event.Post.Title,
+			"PATREON_POST_URL="+event.Post.URL,
+			"PATREON_POST_PUBLISHED_AT="+event.Post.PublishedAt.Format(time.RFC3339),
+			"PATREON_POST_MEDIA_COUNT="+strconv.Itoa(event.Post.MediaCount),
+		)
+	}
+	return env
+}
+
+func (e *execNotifier) Notify(ctx context.Context, event Event) error {
+	if event.Type != EventNewPost {
+		return nil
+	}
+
+	stdin, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+	cmd := shellCommand(ctx, e.command)
+	// Background processes of the command may keep its output open
+	cmd.WaitDelay = time.Second
+	cmd.Env = append(os.Environ(), eventEnv(event)...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("command failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
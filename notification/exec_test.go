@@ -0,0 +1,68 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/notification"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecNotifier(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	t.Run("passes post metadata via env and stdin", func(t *testing.T) {
+		dir := t.TempDir()
+		envFile := filepath.Join(dir, "env")
+		stdinFile := filepath.Join(dir, "stdin")
+		notifier := notification.NewExecNotifier(`echo "$PATREON_POST_ID|$PATREON_POST_TITLE|$PATREON_POST_MEDIA_COUNT|$PATREON_CREATOR" > "` + envFile + `"; cat > "` + stdinFile + `"`)
+
+		err := notifier.Notify(context.Background(), notification.NewPostEvent("creator", testPost, 2))
+		require.NoError(t, err)
+
+		env, err := os.ReadFile(envFile)
+		require.NoError(t, err)
+		assert.Equal(t, "12345|Some Title|2|creator\n", string(env))
+
+		stdin, err := os.ReadFile(stdinFile)
+		require.NoError(t, err)
+		var event notification.Event
+		require.NoError(t, json.Unmarshal(stdin, &event))
+		assert.Equal(t, notification.EventNewPost, event.Type)
+		assert.Equal(t, "12345", event.Post.ID)
+	})
+
+	t.Run("ignores other events", func(t *testing.T) {
+		notifier := notification.NewExecNotifier("exit 1")
+
+		err := notifier.Notify(context.Background(), notification.AuthFailedEvent(assert.AnError))
+		assert.NoError(t, err)
+	})
+
+	t.Run("stops hung commands", func(t *testing.T) {
+		notifier := notification.NewExecNotifier("sleep 30")
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := notifier.Notify(ctx, notification.NewPostEvent("creator", testPost, 1))
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("returns the output of failing commands", func(t *testing.T) {
+		notifier := notification.NewExecNotifier("echo broken; exit 1")
+
+		err := notifier.Notify(context.Background(), notification.NewPostEvent("creator", testPost, 1))
+		assert.ErrorContains(t, err, "broken")
+	})
+}
@@ -0,0 +1,112 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+)
+
+type EventType string
+
+const (
+	EventNewPost        EventType = "new_post"
+	EventDownloadFailed EventType = "download_failed"
+	EventAuthFailed     EventType = "auth_failed"
+)
+
+type Post struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+	MediaCount  int       `json:"media_count"`
+}
+
+type Event struct {
+	Type    EventType `json:"type"`
+	Time    time.Time `json:"time"`
+	Creator string    `json:"creator,omitempty"`
+	Post    *Post     `json:"post,omitempty"`
+	MediaID string    `json:"media_id,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+func NewPostEvent(creator string, post patreon.Post, mediaCount int) Event {
+	return Event{
+		Type:    EventNewPost,
+		Time:    time.Now(),
+		Creator: creator,
+		Post:    newPost(post, mediaCount),
+	}
+}
+
+func DownloadFailedEvent(creator string, post patreon.Post, media patreon.Media, err error) Event {
+	return Event{
+		Type:    EventDownloadFailed,
+		Time:    time.Now(),
+		Creator: creator,
+		Post:    newPost(post, 0),
+		MediaID: media.ID,
+		Error:   err.Error(),
+	}
+}
+
+func AuthFailedEvent(err error) Event {
+	return Event{
+		Type:  EventAuthFailed,
+		Time:  time.Now(),
+		Error: err.Error(),
+	}
+}
+
+func newPost(post patreon.Post, mediaCount int) *Post {
+	return &Post{
+		ID:          post.ID,
+		Title:       post.Title,
+		URL:         patreon.PostURL(post.ID),
+		PublishedAt: post.PublishedAt,
+		MediaCount:  mediaCount,
+	}
+}
+
+func (e Event) Message() string {
+	switch e.Type {
+	case EventNewPost:
+		return fmt.Sprintf("New post from %s: %s (%d media files)\n%s", e.Creator, e.Post.Title, e.Post.MediaCount, e.Post.URL)
+	case EventDownloadFailed:
+		return fmt.Sprintf("Failed to download %s from post \"%s\" of %s: %s", e.MediaID, e.Post.Title, e.Creator, e.Error)
+	case EventAuthFailed:
+		return fmt.Sprintf("Authentication with Patreon failed: %s", e.Error)
+	default:
+		return string(e.Type)
+	}
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+type Notifiers []Notifier
+
+func (n Notifiers) Notify(ctx context.Context, event Event) error {
+	var errs []error
+	for _, notifier := range n {
+		err := notifier.Notify(ctx, event)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Send only logs failures, as notifications must not interrupt crawling.
+func Send(ctx context.Context, notifier Notifier, event Event) {
+	err := notifier.Notify(context.WithoutCancel(ctx), event)
+	if err != nil {
+		slog.Warn("failed to send notification", "type", event.Type, "error", err)
+	}
+}
@@ -0,0 +1,79 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/util/logutils"
+	"github.com/MatthiasHarzer/patreon-crawler/util/netutils"
+)
+
+const webhookTimeout = 10 * time.Second
+
+type WebhookFormat string
+
+const (
+	WebhookFormatJSON    WebhookFormat = "json"
+	WebhookFormatDiscord WebhookFormat = "discord"
+	WebhookFormatSlack   WebhookFormat = "slack"
+)
+
+type webhookNotifier struct {
+	url    string
+	format WebhookFormat
+	client *http.Client
+}
+
+func NewWebhookNotifier(webhookURL string, format WebhookFormat, proxy *url.URL) Notifier {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = netutils.ProxyFunc(proxy)
+	return &webhookNotifier{
+		url:    webhookURL,
+		format: format,
+		client: &http.Client{Timeout: webhookTimeout, Transport: transport},
+	}
+}
+
+func (w *webhookNotifier) body(event Event) any {
+	switch w.format {
+	case WebhookFormatDiscord:
+		return map[string]string{"content": event.Message()}
+	case WebhookFormatSlack:
+		return map[string]string{"text": event.Message()}
+	default:
+		return event
+	}
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(w.body(event))
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	slog.Debug("sending webhook", "url", logutils.RedactURL(w.url), "format", w.format, "type", event.Type)
+	response, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("%s webhook returned status %s", w.format, response.Status)
+	}
+	return nil
+}
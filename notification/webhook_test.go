@@ -0,0 +1,111 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/notification"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/util/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPost = patreon.Post{
+	ID:          "12345",
+	Title:       "Some Title",
+	PublishedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+}
+
+// webhookServer returns the URL of a webhook receiving request bodies on the
+// returned channel.
+func webhookServer(t *testing.T, status int) (string, <-chan []byte) {
+	bodies := make(chan []byte, 1)
+	serverURL, closeServer := testutils.HTTPServer(map[string]http.HandlerFunc{
+		"/hook": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			bodies <- body
+			w.WriteHeader(status)
+		},
+	})
+	t.Cleanup(closeServer)
+	return serverURL.JoinPath("hook").String(), bodies
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	t.Run("sends the event as JSON", func(t *testing.T) {
+		url, bodies := webhookServer(t, http.StatusOK)
+		notifier := notification.NewWebhookNotifier(url, notification.WebhookFormatJSON, nil)
+
+		err := notifier.Notify(context.Background(), notification.NewPostEvent("creator", testPost, 3))
+		require.NoError(t, err)
+
+		var event notification.Event
+		require.NoError(t, json.Unmarshal(<-bodies, &event))
+		assert.Equal(t, notification.EventNewPost, event.Type)
+		assert.Equal(t, "creator", event.Creator)
+		require.NotNil(t, event.Post)
+		assert.Equal(t, "12345", event.Post.ID)
+		assert.Equal(t, "Some Title", event.Post.Title)
+		assert.Equal(t, "https://www.patreon.com/posts/12345", event.Post.URL)
+		assert.Equal(t, 3, event.Post.MediaCount)
+		assert.True(t, testPost.PublishedAt.Equal(event.Post.PublishedAt))
+	})
+
+	t.Run("sends the message in the chat format", func(t *testing.T) {
+		tests := []struct {
+			format notification.WebhookFormat
+			key    string
+		}{
+			{format: notification.WebhookFormatDiscord, key: "content"},
+			{format: notification.WebhookFormatSlack, key: "text"},
+		}
+
+		for _, tt := range tests {
+			t.Run(string(tt.format), func(t *testing.T) {
+				url, bodies := webhookServer(t, http.StatusNoContent)
+				notifier := notification.NewWebhookNotifier(url, tt.format, nil)
+				event := notification.AuthFailedEvent(assert.AnError)
+
+				err := notifier.Notify(context.Background(), event)
+				require.NoError(t, err)
+
+				var body map[string]string
+				require.NoError(t, json.Unmarshal(<-bodies, &body))
+				assert.Equal(t, map[string]string{tt.key: event.Message()}, body)
+			})
+		}
+	})
+
+	t.Run("returns an error on non-2xx status", func(t *testing.T) {
+		url, _ := webhookServer(t, http.StatusInternalServerError)
+		notifier := notification.NewWebhookNotifier(url, notification.WebhookFormatJSON, nil)
+
+		err := notifier.Notify(context.Background(), notification.AuthFailedEvent(assert.AnError))
+		assert.ErrorContains(t, err, "500")
+	})
+}
+
+func TestNotifiers(t *testing.T) {
+	t.Run("sends the event to all notifiers", func(t *testing.T) {
+		url1, bodies1 := webhookServer(t, http.StatusOK)
+		url2, bodies2 := webhookServer(t, http.StatusOK)
+		notifiers := notification.Notifiers{
+			notification.NewWebhookNotifier(url1, notification.WebhookFormatJSON, nil),
+			notification.NewWebhookNotifier(url2, notification.WebhookFormatSlack, nil),
+		}
+
+		err := notifiers.Notify(context.Background(), notification.DownloadFailedEvent("creator", testPost, patreon.Media{ID: "m1"}, assert.AnError))
+		require.NoError(t, err)
+		assert.Contains(t, string(<-bodies1), `"download_failed"`)
+		assert.Contains(t, string(<-bodies2), "m1")
+	})
+}
@@ -0,0 +1,5 @@
+package api
+
+type CampaignResponse = Response[ResponseCampaign]
+
+type CampaignsResponse = Response[[]ResponseCampaign]
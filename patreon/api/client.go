@@ -2,59 +2,115 @@ package api
 
 import (
 	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/util/logutils"
+	"github.com/MatthiasHarzer/patreon-crawler/util/netutils"
 )
 
 const apiURL = "https://www.patreon.com/api"
 
 type Client interface {
 	GetCampaign(creatorID string) (ResponseCampaign, error)
+	GetCampaignByID(campaignID string) (CampaignResponse, error)
+	// GetCampaignsByVanity includes campaigns the user is not a member of.
+	GetCampaignsByVanity(vanity string) (CampaignsResponse, error)
 	GetCurrentUser() (UserResponse, error)
+	GetPost(postID string) (PostResponse, error)
 	GetPosts(campaignID string, cursor *string) (PostsResponse, error)
 	IsAuthenticated() (bool, error)
+	SetCookie(cookie string)
 }
 
 type client struct {
-	cookie string
+	httpClient  *http.Client
+	cookieMutex sync.RWMutex
+	cookie      string
 }
 
-func NewClient(cookie string) Client {
+func NewClient(cookie string, proxy *url.URL) Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = netutils.ProxyFunc(proxy)
 	return &client{
-		cookie: cookie,
+		httpClient: &http.Client{Transport: transport},
+		cookie:     cookie,
 	}
 }
 
-func (c *client) doAPIRequest(path string, options map[string]string) (*http.Response, error) {
+func buildRequestURL(path string, options map[string]string) string {
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
 	}
-	urlBuilder := bytes.Buffer{}
-	urlBuilder.WriteString(apiURL)
-	urlBuilder.WriteString(path)
-	urlBuilder.WriteString("?")
-
+	query := url.Values{}
 	for key, value := range options {
-		urlBuilder.WriteString(key)
-		urlBuilder.WriteString("=")
-		urlBuilder.WriteString(value)
-		urlBuilder.WriteString("&")
+		query.Set(key, value)
 	}
-	urlBuilder.Truncate(urlBuilder.Len() - 1)
+	if len(query) == 0 {
+		return apiURL + path
+	}
+	return apiURL + path + "?" + query.Encode()
+}
 
-	requestURL := urlBuilder.String()
+func (c *client) doAPIRequest(path string, options map[string]string) (*http.Response, error) {
+	requestURL := buildRequestURL(path, options)
 
 	request, err := http.NewRequest(http.MethodGet, requestURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	c.cookieMutex.RLock()
 	request.Header.Add("Cookie", c.cookie)
+	c.cookieMutex.RUnlock()
+
+	logURL := logutils.RedactURL(requestURL)
+	slog.Debug("sending api request", "url", logURL)
+	start := time.Now()
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		slog.Warn("api request failed", "url", logURL, "duration", time.Since(start), "error", err)
+		return nil, err
+	}
+
+	slog.Debug("received api response", "url", logURL, "status", response.StatusCode, "duration", time.Since(start))
+	if response.StatusCode != http.StatusOK {
+		slog.Info("api request returned unexpected status", "url", logURL, "status", response.StatusCode)
+	}
+	return response, nil
+}
 
-	client := &http.Client{}
-	return client.Do(request)
+func (c *client) SetCookie(cookie string) {
+	c.cookieMutex.Lock()
+	defer c.cookieMutex.Unlock()
+	c.cookie = cookie
+}
+
+func getResponse[TData any](c *client, path string, options map[string]string, target *Response[TData]) error {
+	response, err := c.doAPIRequest(path, options)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	err = checkResponse(response.StatusCode, body, response.Request.URL.String())
+	if err != nil {
+		return err
+	}
+	return UnmarshalResponse(bytes.NewReader(body), target)
 }
 
 func (c *client) GetCampaign(creatorID string) (ResponseCampaign, error) {
@@ -72,23 +128,49 @@ func (c *client) GetCampaign(creatorID string) (ResponseCampaign, error) {
 		}
 	}
 
-	return ResponseCampaign{}, fmt.Errorf("failed to find campaign for creator ID %s", creatorID)
+	slog.Debug("creator not found in active memberships", "creator", creatorID, "included", len(currentUser.Included))
+	return ResponseCampaign{}, fmt.Errorf("%w for creator ID %s", ErrCampaignNotFound, creatorID)
 }
 
-func (c *client) GetCurrentUser() (UserResponse, error) {
+func (c *client) GetCampaignByID(campaignID string) (CampaignResponse, error) {
 	options := map[string]string{
-		"include":          "active_memberships.campaign",
 		"fields[campaign]": "name,published_at,url,vanity",
 		"json-api-version": "1.0",
 	}
-	response, err := c.doAPIRequest("/current_user", options)
+	var campaignResponse CampaignResponse
+	err := getResponse(c, "/campaigns/"+url.PathEscape(campaignID), options, &campaignResponse)
 	if err != nil {
-		return UserResponse{}, err
+		return CampaignResponse{}, err
+	}
+
+	return campaignResponse, nil
+}
+
+func (c *client) GetCampaignsByVanity(vanity string) (CampaignsResponse, error) {
+	options := map[string]string{
+		"filter[vanity]":   vanity,
+		"fields[campaign]": "name,published_at,url,vanity",
+		"json-api-version": "1.0",
+	}
+	var campaignsResponse CampaignsResponse
+	err := getResponse(c, "/campaigns", options, &campaignsResponse)
+	if err != nil {
+		return CampaignsResponse{}, err
 	}
-	defer response.Body.Close()
 
+	return campaignsResponse, nil
+}
+
+func (c *client) GetCurrentUser() (UserResponse, error) {
+	options := map[string]string{
+		"include":          "active_memberships.campaign,active_memberships.currently_entitled_tiers",
+		"fields[campaign]": "name,published_at,url,vanity",
+		"fields[member]":   "patron_status,is_follower,is_free_member,is_free_trial,pledge_relationship_start,last_charge_date,last_charge_status,campaign_currency,campaign_pledge_amount_cents,access_expires_at",
+		"fields[reward]":   "title,amount_cents,currency,is_free_tier",
+		"json-api-version": "1.0",
+	}
 	var userResponse UserResponse
-	err = UnmarshalResponse(response.Body, &userResponse)
+	err := getResponse(c, "/current_user", options, &userResponse)
 	if err != nil {
 		return UserResponse{}, err
 	}
@@ -96,11 +178,31 @@ func (c *client) GetCurrentUser() (UserResponse, error) {
 	return userResponse, nil
 }
 
+func (c *client) GetPost(postID string) (PostResponse, error) {
+	options := map[string]string{
+		"include":          "campaign,attachments,attachments_media,images,media,user_defined_tags",
+		"fields[post]":     "teaser_text,current_user_can_view,post_metadata,published_at,post_type,title,url,view_count",
+		"fields[media]":    "id,image_urls,download_url,file_name,metadata,mimetype,name,size_bytes",
+		"fields[post_tag]": "tag_type,value",
+		"fields[campaign]": "name,published_at,url,vanity",
+		"json-api-version": "1.0",
+	}
+
+	var postResponse PostResponse
+	err := getResponse(c, "/posts/"+url.PathEscape(postID), options, &postResponse)
+	if err != nil {
+		return PostResponse{}, err
+	}
+
+	return postResponse, nil
+}
+
 func (c *client) GetPosts(campaignID string, cursor *string) (PostsResponse, error) {
 	options := map[string]string{
-		"include":                          "attachments,attachments_media,images,media",
+		"include":                          "attachments,attachments_media,images,media,user_defined_tags",
 		"fields[post]":                     "teaser_text,current_user_can_view,post_metadata,published_at,post_type,title,url,view_count",
-		"fields[media]":                    "id,image_urls,download_url,metadata,mimetype,name,size_bytes",
+		"fields[media]":                    "id,image_urls,download_url,file_name,metadata,mimetype,name,size_bytes",
+		"fields[post_tag]":                 "tag_type,value",
 		"filter[contains_exclusive_posts]": "true",
 		"filter[is_draft]":                 "false",
 		"sort":                             "-published_at",
@@ -111,14 +213,8 @@ func (c *client) GetPosts(campaignID string, cursor *string) (PostsResponse, err
 		options["page[cursor]"] = *cursor
 	}
 
-	response, err := c.doAPIRequest("/posts", options)
-	if err != nil {
-		return PostsResponse{}, err
-	}
-	defer response.Body.Close()
-
 	var postsResponse PostsResponse
-	err = UnmarshalResponse(response.Body, &postsResponse)
+	err := getResponse(c, "/posts", options, &postsResponse)
 	if err != nil {
 		return PostsResponse{}, err
 	}
@@ -127,21 +223,14 @@ func (c *client) GetPosts(campaignID string, cursor *string) (PostsResponse, err
 }
 
 func (c *client) IsAuthenticated() (bool, error) {
-	response, err := c.doAPIRequest("/current_user", nil)
-	if err != nil {
-		return false, fmt.Errorf("failed to get current user: %w", err)
+	var userResponse UserResponse
+	err := getResponse(c, "/current_user", nil, &userResponse)
+	if errors.Is(err, ErrUnauthenticated) {
+		slog.Debug("not authenticated", "error", err)
+		return false, nil
 	}
-	defer response.Body.Close()
-
-	var errorResponse ErrorResponse
-	err = json.NewDecoder(response.Body).Decode(&errorResponse)
 	if err != nil {
-		return false, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if len(errorResponse.Errors) > 0 {
-		return false, nil
+		return false, fmt.Errorf("failed to get current user: %w", err)
 	}
-
 	return true, nil
 }
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Run("sends requests through the proxy", func(t *testing.T) {
+		var method, requestedHost string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method = r.Method
+			requestedHost = r.Host
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer proxy.Close()
+		proxyURL, err := url.Parse(proxy.URL)
+		require.NoError(t, err)
+
+		c := NewClient("session_id=abc", proxyURL)
+		_, err = c.GetCurrentUser()
+
+		// The proxy refuses to tunnel the request to the API
+		assert.Error(t, err)
+		assert.Equal(t, http.MethodConnect, method)
+		assert.Equal(t, "www.patreon.com:443", requestedHost)
+	})
+}
+
+type roundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func respondingClient(statusCode int, body string) *client {
+	return &client{httpClient: &http.Client{Transport: roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		recorder := httptest.NewRecorder()
+		recorder.WriteHeader(statusCode)
+		_, _ = recorder.WriteString(body)
+		response := recorder.Result()
+		response.Request = request
+		return response, nil
+	})}}
+}
+
+func TestIsAuthenticated(t *testing.T) {
+	t.Run("accepts the current user", func(t *testing.T) {
+		authenticated, err := respondingClient(http.StatusOK, `{"data":{"id":"1","type":"user"}}`).IsAuthenticated()
+		require.NoError(t, err)
+		assert.True(t, authenticated)
+	})
+
+	t.Run("rejects unauthenticated responses", func(t *testing.T) {
+		authenticated, err := respondingClient(http.StatusUnauthorized, `{"errors":[{"code_name":"Unauthorized","status":"401"}]}`).IsAuthenticated()
+		require.NoError(t, err)
+		assert.False(t, authenticated)
+	})
+
+	t.Run("returns other errors", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			statusCode int
+			body       string
+		}{
+			{"rate limited", http.StatusTooManyRequests, `{"errors":[{"code_name":"RequestThrottled","status":"429"}]}`},
+			{"server error", http.StatusBadGateway, `<html>Bad Gateway</html>`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := respondingClient(tt.statusCode, tt.body).IsAuthenticated()
+				var apiErr *Error
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, tt.statusCode, apiErr.StatusCode)
+				assert.NotErrorIs(t, err, ErrUnauthenticated)
+			})
+		}
+	})
+}
+
+func TestBuildRequestURL(t *testing.T) {
+	t.Run("escapes query values", func(t *testing.T) {
+		requestURL := buildRequestURL("posts", map[string]string{
+			"page[cursor]":        "a+b/c=",
+			"filter[campaign_id]": "1 2&x=3",
+		})
+
+		parsedURL, err := url.Parse(requestURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/posts", parsedURL.Path)
+		assert.Equal(t, "a+b/c=", parsedURL.Query().Get("page[cursor]"))
+		assert.Equal(t, "1 2&x=3", parsedURL.Query().Get("filter[campaign_id]"))
+		assert.Len(t, parsedURL.Query(), 2)
+	})
+
+	t.Run("omits an empty query", func(t *testing.T) {
+		assert.Equal(t, apiURL+"/current_user", buildRequestURL("/current_user", nil))
+	})
+}
@@ -1,3 +1,5 @@
 package api
 
 type PostsResponse = Response[[]ResponsePost]
+
+type PostResponse = Response[ResponsePost]
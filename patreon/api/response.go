@@ -58,7 +58,8 @@ type ResponseMemberAttributes struct {
 }
 
 type ResponseMemberRelationships struct {
-	Campaign Response[ResponseReference] `json:"campaign"`
+	Campaign               Response[ResponseReference]   `json:"campaign"`
+	CurrentlyEntitledTiers Response[[]ResponseReference] `json:"currently_entitled_tiers"`
 }
 
 type ResponseCampaign = ResponseEntity[ResponseCampaignAttributes, ResponseCampaignRelationships]
@@ -137,10 +138,12 @@ type ResponsePostMetaData struct {
 }
 
 type ResponsePostRelationships struct {
+	Campaign         Response[ResponseReference]               `json:"campaign"`
 	Attachments      ResponsePostRelationshipsAttachments      `json:"attachments"`
 	AttachmentsMedia ResponsePostRelationshipsAttachmentsMedia `json:"attachments_media"`
 	Images           ResponsePostRelationshipsImages           `json:"images"`
 	Media            ResponsePostRelationshipsMedia            `json:"media"`
+	UserDefinedTags  Response[[]ResponseReference]             `json:"user_defined_tags"`
 }
 
 type ResponsePostRelationshipsAttachments struct {
@@ -159,9 +162,17 @@ type ResponsePostRelationshipsMedia struct {
 	Data []ResponseReference `json:"data"`
 }
 
+type ResponsePostTag = ResponseEntity[ResponsePostTagAttributes, any]
+
+type ResponsePostTagAttributes struct {
+	TagType string `json:"tag_type"`
+	Value   string `json:"value"`
+}
+
 type ResponseMedia = ResponseEntity[ResponseMediaAttributes, any]
 
 type ResponseMediaAttributes struct {
+	FileName    string                 `json:"file_name"`
 	SizeBytes   int                    `json:"size_bytes"`
 	MimeType    string                 `json:"mimetype"`
 	DownloadURL string                 `json:"download_url"`
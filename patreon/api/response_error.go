@@ -1,5 +1,19 @@
 package api
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MatthiasHarzer/patreon-crawler/util/logutils"
+)
+
+var ErrUnauthenticated = errors.New("not authenticated with patreon, the cookie is invalid or expired")
+
+var ErrCampaignNotFound = errors.New("campaign not found")
+
 type ErrorResponse struct {
 	Errors []ResponseError `json:"errors"`
 }
@@ -12,3 +26,62 @@ type ResponseError struct {
 	Detail   string `json:"detail"`
 	Status   string `json:"status"`
 }
+
+type Error struct {
+	StatusCode int
+	CodeName   string
+	Detail     string
+	URL        string
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("patreon api returned status %d", e.StatusCode)
+	if e.CodeName != "" {
+		message += fmt.Sprintf(" (%s)", e.CodeName)
+	}
+	if e.Detail != "" {
+		message += ": " + e.Detail
+	}
+	return fmt.Sprintf("%s [%s]", message, e.URL)
+}
+
+const unauthorizedCodeName = "Unauthorized"
+
+// Is does not match a 403 on its own, which usually means the user is not a
+// patron of the creator.
+func (e *Error) Is(target error) bool {
+	return target == ErrUnauthenticated && (e.StatusCode == http.StatusUnauthorized || e.CodeName == unauthorizedCodeName)
+}
+
+func checkResponse(statusCode int, body []byte, requestURL string) error {
+	var errorResponse ErrorResponse
+	// Bodies that are not JSON, such as HTML error pages, carry no details
+	_ = json.Unmarshal(body, &errorResponse)
+
+	if len(errorResponse.Errors) == 0 {
+		if statusCode == http.StatusOK {
+			return nil
+		}
+		return &Error{
+			StatusCode: statusCode,
+			Detail:     http.StatusText(statusCode),
+			URL:        logutils.RedactURL(requestURL),
+		}
+	}
+
+	responseError := errorResponse.Errors[0]
+	// Errors may be returned with a successful HTTP status
+	if errorStatus, err := strconv.Atoi(responseError.Status); err == nil {
+		statusCode = errorStatus
+	}
+	detail := responseError.Detail
+	if detail == "" {
+		detail = responseError.Title
+	}
+	return &Error{
+		StatusCode: statusCode,
+		CodeName:   responseError.CodeName,
+		Detail:     detail,
+		URL:        logutils.RedactURL(requestURL),
+	}
+}
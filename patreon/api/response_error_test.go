@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRequestURL = "https://www.patreon.com/api/posts?filter[campaign_id]=123&token=secret"
+
+func TestCheckResponse(t *testing.T) {
+	t.Run("accepts successful responses", func(t *testing.T) {
+		err := checkResponse(http.StatusOK, []byte(`{"data": []}`), testRequestURL)
+		assert.NoError(t, err)
+	})
+
+	t.Run("detects unauthenticated responses", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			statusCode int
+			body       string
+		}{
+			{name: "401 status", statusCode: http.StatusUnauthorized, body: `<html>Unauthorized</html>`},
+			{name: "403 with auth code name", statusCode: http.StatusForbidden, body: `{"errors": [{"code_name": "Unauthorized", "status": "403"}]}`},
+			{name: "errors array", statusCode: http.StatusOK, body: `{"errors": [{"code_name": "Unauthorized", "detail": "You must be logged in", "status": "401"}]}`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := checkResponse(tt.statusCode, []byte(tt.body), testRequestURL)
+				assert.ErrorIs(t, err, ErrUnauthenticated)
+			})
+		}
+	})
+
+	t.Run("does not treat denied access as unauthenticated", func(t *testing.T) {
+		tests := []struct {
+			name string
+			body string
+		}{
+			{name: "no body", body: ``},
+			{name: "other code name", body: `{"errors": [{"code_name": "Forbidden", "detail": "You do not have access to this post", "status": "403"}]}`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := checkResponse(http.StatusForbidden, []byte(tt.body), testRequestURL)
+				require.Error(t, err)
+				assert.NotErrorIs(t, err, ErrUnauthenticated)
+			})
+		}
+	})
+
+	t.Run("decodes JSON:API errors", func(t *testing.T) {
+		err := checkResponse(http.StatusNotFound, []byte(`{"errors": [{"code_name": "ResourceMissing", "detail": "Post not found", "status": "404"}]}`), testRequestURL)
+		assert.NotErrorIs(t, err, ErrUnauthenticated)
+
+		var apiError *Error
+		require.True(t, errors.As(err, &apiError))
+		assert.Equal(t, http.StatusNotFound, apiError.StatusCode)
+		assert.Equal(t, "ResourceMissing", apiError.CodeName)
+		assert.Equal(t, "Post not found", apiError.Detail)
+		assert.NotContains(t, apiError.URL, "secret")
+		assert.Contains(t, apiError.Error(), "Post not found")
+	})
+
+	t.Run("returns unexpected statuses", func(t *testing.T) {
+		err := checkResponse(http.StatusTooManyRequests, []byte(`<html>Slow down</html>`), testRequestURL)
+
+		var apiError *Error
+		require.True(t, errors.As(err, &apiError))
+		assert.Equal(t, http.StatusTooManyRequests, apiError.StatusCode)
+		assert.Contains(t, err.Error(), "429")
+	})
+}
@@ -37,6 +37,11 @@ func UnmarshalEntity(entityData json.RawMessage) (any, error) {
 		err = json.Unmarshal(entityData, &t)
 		target = t
 		break
+	case "post_tag":
+		t := ResponsePostTag{}
+		err = json.Unmarshal(entityData, &t)
+		target = t
+		break
 	case "reward":
 		t := ResponseReward{}
 		err = json.Unmarshal(entityData, &t)
@@ -18,6 +18,7 @@ var entity = struct {
 	member   testCase
 	user     testCase
 	post     testCase
+	postTag  testCase
 	reward   testCase
 	campaign testCase
 }{
@@ -26,6 +27,7 @@ var entity = struct {
 					"type": "media",
 					"id": "some-id",
 					"attributes": {
+						"file_name": "image.png",
 						"size_bytes": 123456,
 						"mimetype": "image/png",
 						"download_url": "https://example.com/download",
@@ -54,6 +56,7 @@ var entity = struct {
 			ID:   "some-id",
 			Type: "media",
 			Attributes: ResponseMediaAttributes{
+				FileName:    "image.png",
 				SizeBytes:   123456,
 				MimeType:    "image/png",
 				DownloadURL: "https://example.com/download",
@@ -258,6 +261,24 @@ var entity = struct {
 			},
 		},
 	},
+	postTag: testCase{
+		data: `{
+					"type": "post_tag",
+					"id": "user_defined;Test Tag",
+					"attributes": {
+						"tag_type": "user_defined",
+						"value": "Test Tag"
+					}
+				}`,
+		entity: ResponsePostTag{
+			ID:   "user_defined;Test Tag",
+			Type: "post_tag",
+			Attributes: ResponsePostTagAttributes{
+				TagType: "user_defined",
+				Value:   "Test Tag",
+			},
+		},
+	},
 	reward: testCase{
 		data: `{
 					"type": "reward",
@@ -425,6 +446,10 @@ func TestUnmarshalEntity(t *testing.T) {
 				name:     "post",
 				testCase: entity.post,
 			},
+			{
+				name:     "post_tag",
+				testCase: entity.postTag,
+			},
 			{
 				name:     "reward",
 				testCase: entity.reward,
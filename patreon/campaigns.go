@@ -0,0 +1,189 @@
+package patreon
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+)
+
+func newCampaign(responseCampaign api.ResponseCampaign) Campaign {
+	return Campaign{
+		ID:     responseCampaign.ID,
+		Name:   responseCampaign.Attributes.Name,
+		Vanity: responseCampaign.Attributes.Vanity,
+		URL:    responseCampaign.Attributes.URL,
+	}
+}
+
+// ResolveCampaign falls back to the campaign lookup for vanities the user is
+// not a member of.
+func ResolveCampaign(apiClient api.Client, creator string) (Campaign, error) {
+	ref, err := ParseCreatorRef(creator)
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	if ref.PostID != "" {
+		_, campaign, err := GetPost(apiClient, ref.PostID)
+		if err != nil {
+			return Campaign{}, fmt.Errorf("failed to get creator of post %s: %w", ref.PostID, err)
+		}
+		return campaign, nil
+	}
+
+	if ref.CampaignID != "" {
+		response, err := apiClient.GetCampaignByID(ref.CampaignID)
+		var apiError *api.Error
+		if errors.As(err, &apiError) && apiError.StatusCode == http.StatusNotFound {
+			return Campaign{}, fmt.Errorf("%w for campaign ID %s", api.ErrCampaignNotFound, ref.CampaignID)
+		}
+		if err != nil {
+			return Campaign{}, err
+		}
+		return newCampaign(response.Data), nil
+	}
+
+	responseCampaign, err := apiClient.GetCampaign(ref.Vanity)
+	if err == nil {
+		return newCampaign(responseCampaign), nil
+	}
+	if !errors.Is(err, api.ErrCampaignNotFound) {
+		return Campaign{}, err
+	}
+
+	slog.Debug("looking up campaign by vanity", "vanity", ref.Vanity)
+	response, err := apiClient.GetCampaignsByVanity(ref.Vanity)
+	if err != nil {
+		return Campaign{}, err
+	}
+	for _, responseCampaign := range response.Data {
+		if strings.EqualFold(responseCampaign.Attributes.Vanity, ref.Vanity) {
+			return newCampaign(responseCampaign), nil
+		}
+	}
+	return Campaign{}, fmt.Errorf("%w for creator ID %s", api.ErrCampaignNotFound, ref.Vanity)
+}
+
+func GetCampaigns(apiClient api.Client) ([]Campaign, error) {
+	currentUser, err := apiClient.GetCurrentUser()
+	if err != nil {
+		return nil, err
+	}
+
+	var campaigns []Campaign
+	for _, include := range currentUser.Included {
+		switch include := include.(type) {
+		case api.ResponseCampaign:
+			campaigns = append(campaigns, newCampaign(include))
+		}
+	}
+
+	return campaigns, nil
+}
+
+func GetMemberships(apiClient api.Client) ([]Membership, error) {
+	currentUser, err := apiClient.GetCurrentUser()
+	if err != nil {
+		return nil, err
+	}
+
+	campaigns := make(map[string]Campaign)
+	tiers := make(map[string]string)
+	var members []api.ResponseMember
+	for _, include := range currentUser.Included {
+		switch include := include.(type) {
+		case api.ResponseCampaign:
+			campaigns[include.ID] = newCampaign(include)
+		case api.ResponseReward:
+			tiers[include.ID] = include.Attributes.Title
+		case api.ResponseMember:
+			members = append(members, include)
+		}
+	}
+
+	var memberships []Membership
+	for _, member := range members {
+		campaign, ok := campaigns[member.RelationShips.Campaign.Data.ID]
+		if !ok {
+			continue
+		}
+
+		var memberTiers []string
+		for _, ref := range member.RelationShips.CurrentlyEntitledTiers.Data {
+			title, ok := tiers[ref.ID]
+			if !ok || title == "" {
+				continue
+			}
+			memberTiers = append(memberTiers, title)
+		}
+
+		var accessExpiresAt time.Time
+		if member.Attributes.AccessExpiresAt != "" {
+			accessExpiresAt, err = time.Parse(time.RFC3339, member.Attributes.AccessExpiresAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse access expiry of membership %s: %w", member.ID, err)
+			}
+		}
+
+		memberships = append(memberships, Membership{
+			Campaign:          campaign,
+			PatronStatus:      member.Attributes.PatronStatus,
+			PledgeAmountCents: member.Attributes.CampaignPledgeAmountCents,
+			Currency:          member.Attributes.CampaignCurrency,
+			Tiers:             memberTiers,
+			AccessExpiresAt:   accessExpiresAt,
+		})
+	}
+
+	return memberships, nil
+}
+
+func matchesAnyPattern(patterns []string, vanity string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(vanity))
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MatchesCreatorPatterns matches case-insensitively. No include patterns
+// include every vanity.
+func MatchesCreatorPatterns(vanity string, include, exclude []string) (bool, error) {
+	if len(include) > 0 {
+		included, err := matchesAnyPattern(include, vanity)
+		if err != nil || !included {
+			return false, err
+		}
+	}
+
+	excluded, err := matchesAnyPattern(exclude, vanity)
+	if err != nil {
+		return false, err
+	}
+	return !excluded, nil
+}
+
+func FilterCampaigns(campaigns []Campaign, include, exclude []string) ([]Campaign, error) {
+	var filtered []Campaign
+	for _, campaign := range campaigns {
+		matches, err := MatchesCreatorPatterns(campaign.Vanity, include, exclude)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			filtered = append(filtered, campaign)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,275 @@
+package patreon_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAPIClient struct {
+	api.Client
+	currentUser       api.UserResponse
+	post              api.PostResponse
+	campaignsByID     map[string]api.ResponseCampaign
+	campaignsByVanity api.CampaignsResponse
+}
+
+func (f *fakeAPIClient) GetCampaign(creatorID string) (api.ResponseCampaign, error) {
+	for _, include := range f.currentUser.Included {
+		if campaign, ok := include.(api.ResponseCampaign); ok && campaign.Attributes.Vanity == creatorID {
+			return campaign, nil
+		}
+	}
+	return api.ResponseCampaign{}, api.ErrCampaignNotFound
+}
+
+func (f *fakeAPIClient) GetCampaignByID(campaignID string) (api.CampaignResponse, error) {
+	campaign, ok := f.campaignsByID[campaignID]
+	if !ok {
+		return api.CampaignResponse{}, &api.Error{StatusCode: http.StatusNotFound}
+	}
+	return api.CampaignResponse{Data: campaign}, nil
+}
+
+func (f *fakeAPIClient) GetCampaignsByVanity(_ string) (api.CampaignsResponse, error) {
+	return f.campaignsByVanity, nil
+}
+
+func (f *fakeAPIClient) GetCurrentUser() (api.UserResponse, error) {
+	return f.currentUser, nil
+}
+
+func (f *fakeAPIClient) GetPost(_ string) (api.PostResponse, error) {
+	return f.post, nil
+}
+
+func vanities(campaigns []patreon.Campaign) []string {
+	result := make([]string, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		result = append(result, campaign.Vanity)
+	}
+	return result
+}
+
+func TestResolveCampaign(t *testing.T) {
+	campaign := api.ResponseCampaign{
+		ID:         "123",
+		Type:       "campaign",
+		Attributes: api.ResponseCampaignAttributes{Name: "Test Campaign", Vanity: "test-campaign"},
+	}
+
+	t.Run("resolves memberships", func(t *testing.T) {
+		apiClient := &fakeAPIClient{currentUser: api.UserResponse{Included: []any{campaign}}}
+
+		resolved, err := patreon.ResolveCampaign(apiClient, "test-campaign")
+		require.NoError(t, err)
+		assert.Equal(t, "123", resolved.ID)
+	})
+
+	t.Run("looks up vanities of creators without membership", func(t *testing.T) {
+		apiClient := &fakeAPIClient{
+			campaignsByVanity: api.CampaignsResponse{Data: []api.ResponseCampaign{campaign}},
+		}
+
+		resolved, err := patreon.ResolveCampaign(apiClient, "https://www.patreon.com/c/Test-Campaign")
+		require.NoError(t, err)
+		assert.Equal(t, "123", resolved.ID)
+		assert.Equal(t, "test-campaign", resolved.Vanity)
+	})
+
+	t.Run("resolves numeric campaign IDs", func(t *testing.T) {
+		apiClient := &fakeAPIClient{campaignsByID: map[string]api.ResponseCampaign{"123": campaign}}
+
+		resolved, err := patreon.ResolveCampaign(apiClient, "123")
+		require.NoError(t, err)
+		assert.Equal(t, "test-campaign", resolved.Vanity)
+	})
+
+	t.Run("resolves the creator of posts", func(t *testing.T) {
+		apiClient := &fakeAPIClient{
+			post: api.PostResponse{
+				Data: api.ResponsePost{
+					ID:   "12345",
+					Type: "post",
+					Attributes: api.ResponsePostAttributes{
+						PublishedAt: "2025-01-01T00:00:00Z",
+					},
+					RelationShips: api.ResponsePostRelationships{
+						Campaign: api.Response[api.ResponseReference]{
+							Data: api.ResponseReference{ID: "123", Type: "campaign"},
+						},
+					},
+				},
+				Included: []any{campaign},
+			},
+		}
+
+		resolved, err := patreon.ResolveCampaign(apiClient, "https://www.patreon.com/posts/some-title-12345")
+		require.NoError(t, err)
+		assert.Equal(t, "test-campaign", resolved.Vanity)
+	})
+
+	t.Run("fails for unknown creators", func(t *testing.T) {
+		apiClient := &fakeAPIClient{}
+
+		_, err := patreon.ResolveCampaign(apiClient, "unknown")
+		assert.ErrorIs(t, err, api.ErrCampaignNotFound)
+
+		_, err = patreon.ResolveCampaign(apiClient, "456")
+		assert.ErrorIs(t, err, api.ErrCampaignNotFound)
+	})
+}
+
+func TestGetCampaigns(t *testing.T) {
+	t.Run("returns included campaigns", func(t *testing.T) {
+		apiClient := &fakeAPIClient{
+			currentUser: api.UserResponse{
+				Included: []any{
+					api.ResponseMember{ID: "member-id", Type: "member"},
+					api.ResponseCampaign{
+						ID:   "campaign-id",
+						Type: "campaign",
+						Attributes: api.ResponseCampaignAttributes{
+							Name:   "Test Campaign",
+							Vanity: "test-campaign",
+							URL:    "https://www.patreon.com/test-campaign",
+						},
+					},
+				},
+			},
+		}
+
+		campaigns, err := patreon.GetCampaigns(apiClient)
+		require.NoError(t, err)
+		require.Len(t, campaigns, 1)
+		assert.Equal(t, patreon.Campaign{
+			ID:     "campaign-id",
+			Name:   "Test Campaign",
+			Vanity: "test-campaign",
+			URL:    "https://www.patreon.com/test-campaign",
+		}, campaigns[0])
+	})
+}
+
+func TestGetMemberships(t *testing.T) {
+	campaign := api.ResponseCampaign{
+		ID:   "campaign-id",
+		Type: "campaign",
+		Attributes: api.ResponseCampaignAttributes{
+			Name:   "Test Campaign",
+			Vanity: "test-campaign",
+		},
+	}
+	tier := api.ResponseReward{
+		ID:         "reward-id",
+		Type:       "reward",
+		Attributes: api.ResponseRewardAttributes{Title: "Gold"},
+	}
+
+	t.Run("decodes members with campaign and tiers", func(t *testing.T) {
+		apiClient := &fakeAPIClient{
+			currentUser: api.UserResponse{
+				Included: []any{
+					api.ResponseMember{
+						ID:   "member-id",
+						Type: "member",
+						Attributes: api.ResponseMemberAttributes{
+							PatronStatus:              "active_patron",
+							CampaignCurrency:          "USD",
+							CampaignPledgeAmountCents: 500,
+							AccessExpiresAt:           "2025-12-31T23:59:59Z",
+						},
+						RelationShips: api.ResponseMemberRelationships{
+							Campaign: api.Response[api.ResponseReference]{
+								Data: api.ResponseReference{ID: "campaign-id", Type: "campaign"},
+							},
+							CurrentlyEntitledTiers: api.Response[[]api.ResponseReference]{
+								Data: []api.ResponseReference{{ID: "reward-id", Type: "reward"}},
+							},
+						},
+					},
+					campaign,
+					tier,
+				},
+			},
+		}
+
+		memberships, err := patreon.GetMemberships(apiClient)
+		require.NoError(t, err)
+		require.Len(t, memberships, 1)
+
+		membership := memberships[0]
+		assert.Equal(t, "test-campaign", membership.Campaign.Vanity)
+		assert.Equal(t, "active_patron", membership.PatronStatus)
+		assert.Equal(t, 500, membership.PledgeAmountCents)
+		assert.Equal(t, "USD", membership.Currency)
+		assert.Equal(t, []string{"Gold"}, membership.Tiers)
+		assert.Equal(t, time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC), membership.AccessExpiresAt)
+	})
+
+	t.Run("skips members without included campaign", func(t *testing.T) {
+		apiClient := &fakeAPIClient{
+			currentUser: api.UserResponse{
+				Included: []any{
+					api.ResponseMember{
+						ID:   "member-id",
+						Type: "member",
+						RelationShips: api.ResponseMemberRelationships{
+							Campaign: api.Response[api.ResponseReference]{
+								Data: api.ResponseReference{ID: "other-campaign-id", Type: "campaign"},
+							},
+						},
+					},
+					campaign,
+				},
+			},
+		}
+
+		memberships, err := patreon.GetMemberships(apiClient)
+		require.NoError(t, err)
+		assert.Empty(t, memberships)
+	})
+}
+
+func TestFilterCampaigns(t *testing.T) {
+	campaigns := []patreon.Campaign{
+		{Vanity: "artist-one"},
+		{Vanity: "artist-two"},
+		{Vanity: "Musician"},
+	}
+
+	t.Run("no patterns include all campaigns", func(t *testing.T) {
+		filtered, err := patreon.FilterCampaigns(campaigns, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, campaigns, filtered)
+	})
+
+	t.Run("include patterns select matching campaigns", func(t *testing.T) {
+		filtered, err := patreon.FilterCampaigns(campaigns, []string{"artist-*"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"artist-one", "artist-two"}, vanities(filtered))
+	})
+
+	t.Run("exclude patterns take precedence", func(t *testing.T) {
+		filtered, err := patreon.FilterCampaigns(campaigns, []string{"artist-*"}, []string{"*-two"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"artist-one"}, vanities(filtered))
+	})
+
+	t.Run("patterns are case-insensitive", func(t *testing.T) {
+		filtered, err := patreon.FilterCampaigns(campaigns, []string{"MUSIC*"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Musician"}, vanities(filtered))
+	})
+
+	t.Run("fails for invalid patterns", func(t *testing.T) {
+		_, err := patreon.FilterCampaigns(campaigns, []string{"["}, nil)
+		assert.Error(t, err)
+	})
+}
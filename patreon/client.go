@@ -1,7 +1,9 @@
 package patreon
 
 import (
+	"fmt"
 	"iter"
+	"log/slog"
 	"time"
 
 	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
@@ -9,36 +11,39 @@ import (
 
 type Client interface {
 	Posts() iter.Seq2[Post, error]
-	VanityID() string
+	CreatorID() string
 }
 
 type client struct {
-	apiClient        api.Client
-	campaignID       string
-	campaignVanityID string
+	apiClient  api.Client
+	campaignID string
+	creatorID  string
 }
 
 func NewClient(apiClient api.Client, creatorID string) (Client, error) {
-	campaign, err := apiClient.GetCampaign(creatorID)
+	campaign, err := ResolveCampaign(apiClient, creatorID)
 	if err != nil {
 		return nil, err
 	}
-	return &client{
-		apiClient:        apiClient,
-		campaignID:       campaign.ID,
-		campaignVanityID: campaign.Attributes.Vanity,
-	}, nil
+	slog.Debug("resolved campaign", "creator", creatorID, "campaign", campaign.ID, "vanity", campaign.Vanity)
+	return NewCampaignClient(apiClient, campaign), nil
 }
 
-func (c *client) getPosts(cursor string) ([]Post, string, error) {
-	postsResponse, err := c.apiClient.GetPosts(c.campaignID, &cursor)
-	if err != nil {
-		return nil, "", err
+func NewCampaignClient(apiClient api.Client, campaign Campaign) Client {
+	return &client{
+		apiClient:  apiClient,
+		campaignID: campaign.ID,
+		creatorID:  campaign.CreatorID(),
 	}
+}
 
+func parsePosts(responsePosts []api.ResponsePost, included []any) ([]Post, error) {
 	medias := make(map[string]Media)
-	for _, include := range postsResponse.Included {
+	tags := make(map[string]string)
+	for _, include := range included {
 		switch include := include.(type) {
+		case api.ResponsePostTag:
+			tags[include.ID] = include.Attributes.Value
 		case api.ResponseMedia:
 			downloadURL := include.Attributes.DownloadURL
 			if downloadURL == "" {
@@ -47,16 +52,18 @@ func (c *client) getPosts(cursor string) ([]Post, string, error) {
 
 			medias[include.ID] = Media{
 				ID:          include.ID,
+				FileName:    include.Attributes.FileName,
 				DownloadURL: downloadURL,
 				MimeType:    include.Attributes.MimeType,
 				Height:      include.Attributes.Metadata.Dimensions.H,
 				Width:       include.Attributes.Metadata.Dimensions.W,
+				SizeBytes:   int64(include.Attributes.SizeBytes),
 			}
 		}
 	}
 
 	var posts []Post
-	for _, responsePost := range postsResponse.Data {
+	for _, responsePost := range responsePosts {
 		if responsePost.Type != "post" {
 			continue
 		}
@@ -82,14 +89,26 @@ func (c *client) getPosts(cursor string) ([]Post, string, error) {
 			attachments = append(attachments, m)
 		}
 
+		var postTags []string
+		for _, ref := range responsePost.RelationShips.UserDefinedTags.Data {
+			tag, ok := tags[ref.ID]
+			if !ok {
+				continue
+			}
+
+			postTags = append(postTags, tag)
+		}
+
 		publishedAt, err := time.Parse(time.RFC3339, responsePost.Attributes.PublishedAt)
 		if err != nil {
-			return nil, "", err
+			return nil, err
 		}
 
 		posts = append(posts, Post{
 			ID:                 responsePost.ID,
 			Title:              responsePost.Attributes.Title,
+			PostType:           responsePost.Attributes.PostType,
+			Tags:               postTags,
 			Media:              media,
 			Attachments:        attachments,
 			PublishedAt:        publishedAt,
@@ -97,24 +116,68 @@ func (c *client) getPosts(cursor string) ([]Post, string, error) {
 		})
 	}
 
+	return posts, nil
+}
+
+func (c *client) getPosts(cursor string) ([]Post, string, error) {
+	postsResponse, err := c.apiClient.GetPosts(c.campaignID, &cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	posts, err := parsePosts(postsResponse.Data, postsResponse.Included)
+	if err != nil {
+		return nil, "", err
+	}
+
 	nextCursor := postsResponse.Meta.Pagination.Cursors.Next
 
 	return posts, nextCursor, nil
 }
 
-func (c *client) VanityID() string {
-	return c.campaignVanityID
+func GetPost(apiClient api.Client, postID string) (Post, Campaign, error) {
+	postResponse, err := apiClient.GetPost(postID)
+	if err != nil {
+		return Post{}, Campaign{}, err
+	}
+
+	posts, err := parsePosts([]api.ResponsePost{postResponse.Data}, postResponse.Included)
+	if err != nil {
+		return Post{}, Campaign{}, err
+	}
+	if len(posts) == 0 {
+		return Post{}, Campaign{}, fmt.Errorf("failed to find post with ID %s", postID)
+	}
+
+	campaignID := postResponse.Data.RelationShips.Campaign.Data.ID
+	for _, include := range postResponse.Included {
+		switch include := include.(type) {
+		case api.ResponseCampaign:
+			if include.ID == campaignID {
+				return posts[0], newCampaign(include), nil
+			}
+		}
+	}
+
+	return Post{}, Campaign{}, fmt.Errorf("failed to find campaign of post %s", postID)
+}
+
+func (c *client) CreatorID() string {
+	return c.creatorID
 }
 
 func (c *client) Posts() iter.Seq2[Post, error] {
 	return func(yield func(Post, error) bool) {
 		var cursor string
 		for {
+			slog.Debug("fetching posts page", "campaign", c.campaignID, "cursor", cursor)
 			posts, nextCursor, err := c.getPosts(cursor)
 			if err != nil {
+				slog.Debug("failed to fetch posts page", "campaign", c.campaignID, "cursor", cursor, "error", err)
 				yield(Post{}, err)
 				return
 			}
+			slog.Debug("fetched posts page", "campaign", c.campaignID, "posts", len(posts), "next_cursor", nextCursor)
 
 			for _, post := range posts {
 				if !yield(post, nil) {
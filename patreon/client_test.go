@@ -0,0 +1,113 @@
+package patreon_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPost(t *testing.T) {
+	responsePost := api.ResponsePost{
+		ID:   "post-id",
+		Type: "post",
+		Attributes: api.ResponsePostAttributes{
+			Title:              "Test Post",
+			PostType:           "image_file",
+			PublishedAt:        "2025-01-01T00:00:00Z",
+			CurrentUserCanView: true,
+			PostMetaData:       api.ResponsePostMetaData{ImageOrder: []string{"media-id"}},
+		},
+		RelationShips: api.ResponsePostRelationships{
+			Campaign: api.Response[api.ResponseReference]{
+				Data: api.ResponseReference{ID: "campaign-id", Type: "campaign"},
+			},
+			AttachmentsMedia: api.ResponsePostRelationshipsAttachmentsMedia{
+				Data: []api.ResponseReference{{ID: "attachment-id", Type: "media"}},
+			},
+			UserDefinedTags: api.Response[[]api.ResponseReference]{
+				Data: []api.ResponseReference{{ID: "user_defined;Sketch", Type: "post_tag"}},
+			},
+		},
+	}
+	included := []any{
+		api.ResponsePostTag{
+			ID:         "user_defined;Sketch",
+			Type:       "post_tag",
+			Attributes: api.ResponsePostTagAttributes{TagType: "user_defined", Value: "Sketch"},
+		},
+		api.ResponseMedia{
+			ID:   "media-id",
+			Type: "media",
+			Attributes: api.ResponseMediaAttributes{
+				MimeType:  "image/png",
+				ImageURLs: api.ResponseMediaImageURLs{Original: "https://example.com/original.png"},
+			},
+		},
+		api.ResponseMedia{
+			ID:   "attachment-id",
+			Type: "media",
+			Attributes: api.ResponseMediaAttributes{
+				MimeType:    "application/zip",
+				DownloadURL: "https://example.com/download.zip",
+			},
+		},
+		api.ResponseCampaign{
+			ID:         "campaign-id",
+			Type:       "campaign",
+			Attributes: api.ResponseCampaignAttributes{Vanity: "test-campaign"},
+		},
+	}
+
+	t.Run("returns post with media and campaign", func(t *testing.T) {
+		apiClient := &fakeAPIClient{
+			post: api.PostResponse{Data: responsePost, Included: included},
+		}
+
+		post, campaign, err := patreon.GetPost(apiClient, "post-id")
+		require.NoError(t, err)
+
+		assert.Equal(t, "post-id", post.ID)
+		assert.Equal(t, "Test Post", post.Title)
+		assert.Equal(t, "image_file", post.PostType)
+		assert.Equal(t, []string{"Sketch"}, post.Tags)
+		assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), post.PublishedAt)
+		require.Len(t, post.Media, 1)
+		assert.Equal(t, "https://example.com/original.png", post.Media[0].DownloadURL)
+		require.Len(t, post.Attachments, 1)
+		assert.Equal(t, "https://example.com/download.zip", post.Attachments[0].DownloadURL)
+		assert.Equal(t, "test-campaign", campaign.Vanity)
+	})
+
+	t.Run("fails without included campaign", func(t *testing.T) {
+		apiClient := &fakeAPIClient{
+			post: api.PostResponse{Data: responsePost, Included: included[:3]},
+		}
+
+		_, _, err := patreon.GetPost(apiClient, "post-id")
+		assert.Error(t, err)
+	})
+
+	t.Run("fails for empty responses", func(t *testing.T) {
+		apiClient := &fakeAPIClient{}
+
+		_, _, err := patreon.GetPost(apiClient, "post-id")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewCampaignClient(t *testing.T) {
+	t.Run("uses the vanity as creator ID", func(t *testing.T) {
+		client := patreon.NewCampaignClient(&fakeAPIClient{}, patreon.Campaign{ID: "123", Vanity: "creator"})
+		assert.Equal(t, "creator", client.CreatorID())
+	})
+
+	t.Run("falls back to the campaign ID without a vanity", func(t *testing.T) {
+		client := patreon.NewCampaignClient(&fakeAPIClient{}, patreon.Campaign{ID: "123"})
+		assert.Equal(t, "123", client.CreatorID())
+	})
+}
@@ -5,6 +5,8 @@ import "time"
 type Post struct {
 	ID                 string
 	Title              string
+	PostType           string
+	Tags               []string
 	Media              []Media
 	Attachments        []Media
 	PublishedAt        time.Time
@@ -13,8 +15,43 @@ type Post struct {
 
 type Media struct {
 	ID          string
+	FileName    string
 	Height      int
 	Width       int
 	DownloadURL string
 	MimeType    string
+	// SizeBytes is 0 if the size is unknown.
+	SizeBytes int64
+}
+
+type Campaign struct {
+	ID     string
+	Name   string
+	Vanity string
+	URL    string
+}
+
+// CreatorID falls back to the campaign ID for campaigns without a vanity.
+func (c Campaign) CreatorID() string {
+	if c.Vanity != "" {
+		return c.Vanity
+	}
+	return c.ID
+}
+
+type User struct {
+	ID              string
+	Name            string
+	Email           string
+	MembershipCount int
+}
+
+type Membership struct {
+	Campaign          Campaign
+	PatronStatus      string
+	PledgeAmountCents int
+	Currency          string
+	Tiers             []string
+	// AccessExpiresAt is the zero time if access does not expire.
+	AccessExpiresAt time.Time
 }
@@ -0,0 +1,105 @@
+package patreon
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var numericID = regexp.MustCompile(`^[0-9]+$`)
+
+var postURLSlugID = regexp.MustCompile(`(?:^|-)([0-9]+)$`)
+
+func ParsePostID(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if numericID.MatchString(value) {
+		return value, nil
+	}
+
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	parsedURL, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid post URL %q: %w", value, err)
+	}
+
+	segments := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if len(segments) != 2 || segments[0] != "posts" {
+		return "", fmt.Errorf("invalid post URL %q. Must be of the form patreon.com/posts/<title>-<id>", value)
+	}
+
+	match := postURLSlugID.FindStringSubmatch(segments[1])
+	if match == nil {
+		return "", fmt.Errorf("invalid post URL %q. Must be of the form patreon.com/posts/<title>-<id>", value)
+	}
+	return match[1], nil
+}
+
+type CreatorRef struct {
+	CampaignID string
+	Vanity     string
+	PostID     string
+}
+
+var reservedPaths = map[string]bool{
+	"c": true, "cw": true, "home": true, "login": true, "signup": true, "settings": true, "messages": true,
+	"notifications": true, "search": true, "explore": true, "posts": true, "user": true, "api": true,
+}
+
+var creatorSubpages = map[string]bool{
+	"posts": true, "about": true, "membership": true, "collections": true, "shop": true, "home": true,
+}
+
+// ParseCreatorRef parses a vanity name, a numeric campaign ID or a creator or
+// post URL. Post URLs refer to the creator of the post.
+func ParseCreatorRef(value string) (CreatorRef, error) {
+	value = strings.TrimSpace(value)
+	if numericID.MatchString(value) {
+		return CreatorRef{CampaignID: value}, nil
+	}
+	if !strings.Contains(value, "/") {
+		if value == "" {
+			return CreatorRef{}, fmt.Errorf("creator must not be empty")
+		}
+		return CreatorRef{Vanity: value}, nil
+	}
+
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	parsedURL, err := url.Parse(value)
+	if err != nil {
+		return CreatorRef{}, fmt.Errorf("invalid creator URL %q: %w", value, err)
+	}
+	host := strings.ToLower(parsedURL.Hostname())
+	if host != "patreon.com" && !strings.HasSuffix(host, ".patreon.com") {
+		return CreatorRef{}, fmt.Errorf("invalid creator URL %q. Must be a patreon.com URL", value)
+	}
+
+	segments := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	switch {
+	case segments[0] == "posts":
+		postID, err := ParsePostID(value)
+		if err != nil {
+			return CreatorRef{}, err
+		}
+		return CreatorRef{PostID: postID}, nil
+	case segments[0] == "c" && len(segments) >= 2 && segments[1] != "":
+		if len(segments) > 2 && !creatorSubpages[segments[2]] {
+			break
+		}
+		return CreatorRef{Vanity: segments[1]}, nil
+	case segments[0] != "" && !reservedPaths[segments[0]]:
+		if len(segments) > 1 && !creatorSubpages[segments[1]] {
+			break
+		}
+		return CreatorRef{Vanity: segments[0]}, nil
+	}
+	return CreatorRef{}, fmt.Errorf("invalid creator URL %q. Must be of the form patreon.com/c/<name>, patreon.com/<name> or patreon.com/posts/<title>-<id>", value)
+}
+
+func PostURL(postID string) string {
+	return "https://www.patreon.com/posts/" + url.PathEscape(postID)
+}
@@ -0,0 +1,93 @@
+package patreon_test
+
+import (
+	"testing"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePostID(t *testing.T) {
+	t.Run("parses valid references", func(t *testing.T) {
+		tests := []struct {
+			value    string
+			expected string
+		}{
+			{value: "12345", expected: "12345"},
+			{value: "https://www.patreon.com/posts/some-title-12345", expected: "12345"},
+			{value: "https://www.patreon.com/posts/some-title-12345?utm_source=share", expected: "12345"},
+			{value: "patreon.com/posts/12345", expected: "12345"},
+			{value: "www.patreon.com/posts/title-2024-release-12345/", expected: "12345"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.value, func(t *testing.T) {
+				postID, err := patreon.ParsePostID(tt.value)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, postID)
+			})
+		}
+	})
+
+	t.Run("fails for invalid references", func(t *testing.T) {
+		values := []string{
+			"",
+			"some-title",
+			"https://www.patreon.com/c/creator",
+			"https://www.patreon.com/posts/some-title",
+		}
+
+		for _, value := range values {
+			t.Run(value, func(t *testing.T) {
+				_, err := patreon.ParsePostID(value)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestParseCreatorRef(t *testing.T) {
+	t.Run("parses valid references", func(t *testing.T) {
+		tests := []struct {
+			value    string
+			expected patreon.CreatorRef
+		}{
+			{value: "creator", expected: patreon.CreatorRef{Vanity: "creator"}},
+			{value: "12345", expected: patreon.CreatorRef{CampaignID: "12345"}},
+			{value: "https://www.patreon.com/c/creator", expected: patreon.CreatorRef{Vanity: "creator"}},
+			{value: "patreon.com/c/creator/", expected: patreon.CreatorRef{Vanity: "creator"}},
+			{value: "https://www.patreon.com/c/creator/posts?filters[tag]=art", expected: patreon.CreatorRef{Vanity: "creator"}},
+			{value: "patreon.com/creator", expected: patreon.CreatorRef{Vanity: "creator"}},
+			{value: "https://patreon.com/creator/posts", expected: patreon.CreatorRef{Vanity: "creator"}},
+			{value: "patreon.com/posts/some-title-12345", expected: patreon.CreatorRef{PostID: "12345"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.value, func(t *testing.T) {
+				ref, err := patreon.ParseCreatorRef(tt.value)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, ref)
+			})
+		}
+	})
+
+	t.Run("fails for invalid references", func(t *testing.T) {
+		values := []string{
+			"",
+			"https://www.patreon.com/c/",
+			"https://www.patreon.com/home",
+			"https://www.patreon.com/posts/some-title",
+			"https://www.patreon.com/c/creator/unknown/page",
+			"https://example.com/creator",
+		}
+
+		for _, value := range values {
+			t.Run(value, func(t *testing.T) {
+				_, err := patreon.ParseCreatorRef(value)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
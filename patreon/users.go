@@ -0,0 +1,34 @@
+package patreon
+
+import (
+	"strings"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+)
+
+func GetCurrentUser(apiClient api.Client) (User, error) {
+	currentUser, err := apiClient.GetCurrentUser()
+	if err != nil {
+		return User{}, err
+	}
+
+	attributes := currentUser.Data.Attributes
+	name := attributes.FullName
+	if name == "" {
+		name = strings.TrimSpace(attributes.FirstName + " " + attributes.LastName)
+	}
+
+	membershipCount := 0
+	for _, include := range currentUser.Included {
+		if _, ok := include.(api.ResponseMember); ok {
+			membershipCount++
+		}
+	}
+
+	return User{
+		ID:              currentUser.Data.ID,
+		Name:            name,
+		Email:           attributes.Email,
+		MembershipCount: membershipCount,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package patreon_test
+
+import (
+	"testing"
+
+	"github.com/MatthiasHarzer/patreon-crawler/patreon"
+	"github.com/MatthiasHarzer/patreon-crawler/patreon/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCurrentUser(t *testing.T) {
+	t.Run("returns the user with the membership count", func(t *testing.T) {
+		apiClient := &fakeAPIClient{
+			currentUser: api.UserResponse{
+				Data: api.ResponseUser{
+					ID:   "user-id",
+					Type: "user",
+					Attributes: api.ResponseUserAttributes{
+						Email:    "user@example.com",
+						FullName: "Some User",
+					},
+				},
+				Included: []any{
+					api.ResponseMember{ID: "member-1", Type: "member"},
+					api.ResponseCampaign{ID: "campaign-1", Type: "campaign"},
+					api.ResponseMember{ID: "member-2", Type: "member"},
+				},
+			},
+		}
+
+		user, err := patreon.GetCurrentUser(apiClient)
+		require.NoError(t, err)
+		assert.Equal(t, patreon.User{
+			ID:              "user-id",
+			Name:            "Some User",
+			Email:           "user@example.com",
+			MembershipCount: 2,
+		}, user)
+	})
+
+	t.Run("falls back to first and last name", func(t *testing.T) {
+		apiClient := &fakeAPIClient{
+			currentUser: api.UserResponse{
+				Data: api.ResponseUser{
+					Attributes: api.ResponseUserAttributes{
+						FirstName: "Some",
+						LastName:  "User",
+					},
+				},
+			},
+		}
+
+		user, err := patreon.GetCurrentUser(apiClient)
+		require.NoError(t, err)
+		assert.Equal(t, "Some User", user.Name)
+	})
+}
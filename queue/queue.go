@@ -2,83 +2,312 @@ package queue
 
 import (
 	"errors"
+	"log/slog"
+	"slices"
 	"sync"
 )
 
-type Task = func() error
+var ErrStopped = errors.New("queue stopped")
 
-type Queue struct {
-	tasks            []Task
+var ErrClosed = errors.New("queue closed")
+
+type Task[T any] = func() (T, error)
+
+// Priority orders tasks. Tasks of the same priority run in the order they
+// were submitted.
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+type item[T any] struct {
+	batch    *Batch[T]
+	task     Task[T]
+	priority Priority
+}
+
+// Queue is a long-lived worker pool. Its batches share the workers, but fail
+// and report results independently.
+type Queue[T any] struct {
 	concurrencyLimit int
 	mutex            sync.Mutex
-	err              error
-	errMutex         sync.RWMutex
+	// items are sorted by descending priority.
+	items []item[T]
+	// changed is signaled when items are added, a batch is resumed or the
+	// queue is closed.
+	changed   *sync.Cond
+	startOnce sync.Once
+	closed    bool
+	pauses    int
+	workers   sync.WaitGroup
 }
 
-func New(concurrencyLimit int) (*Queue, error) {
+func New[T any](concurrencyLimit int) (*Queue[T], error) {
 	if concurrencyLimit < 1 {
 		return nil, errors.New("concurrency limit must be greater than zero")
 	}
-	return &Queue{
-		tasks:            make([]Task, 0),
+	q := &Queue[T]{
 		concurrencyLimit: concurrencyLimit,
-	}, nil
+	}
+	q.changed = sync.NewCond(&q.mutex)
+	return q, nil
 }
 
-func (q *Queue) next() (Task, bool) {
-	var zero Task
+func (q *Queue[T]) Start() {
+	q.startOnce.Do(func() {
+		slog.Debug("starting queue", "workers", q.concurrencyLimit)
+		q.workers.Add(q.concurrencyLimit)
+		for i := 0; i < q.concurrencyLimit; i++ {
+			go q.worker()
+		}
+	})
+}
+
+// Close drains the queue. Tasks of paused batches are dropped, so Wait
+// returns ErrClosed for their batches.
+func (q *Queue[T]) Close() {
+	slog.Debug("closing queue")
+	q.mutex.Lock()
+	q.closed = true
+	q.changed.Broadcast()
+	q.mutex.Unlock()
+	q.workers.Wait()
+
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	if len(q.tasks) == 0 {
-		return zero, false
+	for len(q.items) > 0 {
+		b := q.items[0].batch
+		if b.err == nil {
+			b.err = ErrClosed
+		}
+		q.dropLocked(b)
 	}
-	task := q.tasks[0]
-	q.tasks = q.tasks[1:]
-	return task, true
 }
 
-func (q *Queue) hasError() bool {
-	q.errMutex.RLock()
-	defer q.errMutex.RUnlock()
-	return q.err != nil
+// next blocks until a task of a batch that is not paused is queued. It
+// returns false once the queue is closed and drained.
+func (q *Queue[T]) next() (item[T], bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for {
+		index := slices.IndexFunc(q.items, func(it item[T]) bool { return !it.batch.paused })
+		if index >= 0 && q.pauses == 0 {
+			it := q.items[index]
+			q.items = slices.Delete(q.items, index, index+1)
+			return it, true
+		}
+		if q.closed {
+			return item[T]{}, false
+		}
+		q.changed.Wait()
+	}
 }
 
-func (q *Queue) worker(wg *sync.WaitGroup) {
-	defer wg.Done()
-
+func (q *Queue[T]) worker() {
+	defer q.workers.Done()
 	for {
-		if q.hasError() {
+		it, ok := q.next()
+		if !ok {
 			return
 		}
+		value, err := it.task()
 
-		task, ok := q.next()
-		if !ok {
-			return
+		q.mutex.Lock()
+		it.batch.results = append(it.batch.results, Result[T]{Value: value, Err: err})
+		if err != nil && it.batch.err == nil {
+			// The first error stops the batch; retries are submitted as new tasks.
+			slog.Debug("queue task failed, not retrying and stopping batch", "error", err)
+			it.batch.err = err
+			q.dropLocked(it.batch)
 		}
-		err := task()
-		if err != nil {
-			q.errMutex.Lock()
-			q.err = err
-			q.errMutex.Unlock()
-			return
+		it.batch.done()
+		q.mutex.Unlock()
+	}
+}
+
+// Pause holds the queued tasks of all batches until Resume. Running tasks
+// finish. Each Pause needs its own Resume.
+func (q *Queue[T]) Pause() {
+	slog.Debug("pausing queue")
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.pauses++
+}
+
+func (q *Queue[T]) Resume() {
+	slog.Debug("resuming queue")
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.pauses > 0 {
+		q.pauses--
+	}
+	q.changed.Broadcast()
+}
+
+// dropLocked must be called with the queue mutex held.
+func (q *Queue[T]) dropLocked(b *Batch[T]) {
+	remaining := q.items[:0]
+	for _, it := range q.items {
+		if it.batch == b {
+			b.dropped++
+			b.done()
+			continue
+		}
+		remaining = append(remaining, it)
+	}
+	clear(q.items[len(remaining):])
+	q.items = remaining
+}
+
+// Batch drops its remaining tasks once a task fails.
+type Batch[T any] struct {
+	queue       *Queue[T]
+	pending     int
+	dropped     int
+	err         error
+	stopped     bool
+	paused      bool
+	closed      bool
+	results     []Result[T]
+	resultsOnce sync.Once
+	resultsChan chan Result[T]
+	changed     *sync.Cond
+}
+
+func (q *Queue[T]) Batch() *Batch[T] {
+	return &Batch[T]{
+		queue:   q,
+		changed: sync.NewCond(&q.mutex),
+	}
+}
+
+// done must be called with the queue mutex held.
+func (b *Batch[T]) done() {
+	b.pending--
+	b.changed.Broadcast()
+}
+
+// ended must be called with the queue mutex held.
+func (b *Batch[T]) ended() bool {
+	return (b.closed || b.stopped || b.err != nil) && b.pending == 0
+}
+
+func (b *Batch[T]) Submit(task Task[T], priority Priority) {
+	q := b.queue
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		if b.err == nil {
+			b.err = ErrClosed
 		}
+		b.changed.Broadcast()
+		return
+	}
+	if b.err != nil || b.stopped {
+		b.dropped++
+		return
 	}
+	b.pending++
+	index := slices.IndexFunc(q.items, func(it item[T]) bool { return it.priority < priority })
+	if index < 0 {
+		index = len(q.items)
+	}
+	q.items = slices.Insert(q.items, index, item[T]{batch: b, task: task, priority: priority})
+	q.changed.Signal()
 }
 
-func (q *Queue) Enqueue(task Task) {
+// Close lets Results close once the submitted tasks are done. Afterwards,
+// only running tasks of the batch may call Submit.
+func (b *Batch[T]) Close() {
+	q := b.queue
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
-	q.tasks = append(q.tasks, task)
+	b.closed = true
+	b.changed.Broadcast()
+}
+
+// Results buffers the results until received, so workers never block.
+func (b *Batch[T]) Results() <-chan Result[T] {
+	b.resultsOnce.Do(func() {
+		b.resultsChan = make(chan Result[T])
+		go b.sendResults()
+	})
+	return b.resultsChan
 }
 
-func (q *Queue) ProcessAll() error {
-	var wg sync.WaitGroup
-	wg.Add(q.concurrencyLimit)
+func (b *Batch[T]) sendResults() {
+	q := b.queue
+	for {
+		q.mutex.Lock()
+		for len(b.results) == 0 && !b.ended() {
+			b.changed.Wait()
+		}
+		if len(b.results) == 0 {
+			q.mutex.Unlock()
+			close(b.resultsChan)
+			return
+		}
+		result := b.results[0]
+		b.results = slices.Delete(b.results, 0, 1)
+		q.mutex.Unlock()
+
+		b.resultsChan <- result
+	}
+}
 
-	for i := 0; i < q.concurrencyLimit; i++ {
-		go q.worker(&wg)
+// Wait returns the error of the first failing task, or ErrStopped if Stop
+// dropped tasks.
+func (b *Batch[T]) Wait() error {
+	q := b.queue
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for b.pending > 0 {
+		b.changed.Wait()
+	}
+	if b.err != nil {
+		return b.err
 	}
-	wg.Wait()
+	if b.stopped && b.dropped > 0 {
+		return ErrStopped
+	}
+	return nil
+}
 
-	return q.err
+// Stop drops the queued tasks of the batch. Running tasks finish.
+func (b *Batch[T]) Stop() {
+	slog.Debug("stopping batch")
+	q := b.queue
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	b.stopped = true
+	b.paused = false
+	q.dropLocked(b)
+	b.changed.Broadcast()
+}
+
+// Pause holds the queued tasks of the batch until Resume. Running tasks
+// finish.
+func (b *Batch[T]) Pause() {
+	slog.Debug("pausing batch")
+	q := b.queue
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	b.paused = true
+}
+
+func (b *Batch[T]) Resume() {
+	slog.Debug("resuming batch")
+	q := b.queue
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	b.paused = false
+	q.changed.Broadcast()
 }
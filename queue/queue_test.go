@@ -19,19 +19,22 @@ func TestQueue(t *testing.T) {
 		processed := make([]int, 0, len(items))
 		var mu sync.Mutex
 
-		q1, err := queue.New(1)
+		q1, err := queue.New[int](1)
 		require.NoError(t, err)
+		defer q1.Close()
+		b1 := q1.Batch()
 		for _, it := range items {
 			it := it
-			q1.Enqueue(func() error {
+			b1.Submit(func() (int, error) {
 				mu.Lock()
 				processed = append(processed, it)
 				mu.Unlock()
-				return nil
-			})
+				return 0, nil
+			}, queue.PriorityNormal)
 		}
 
-		err = q1.ProcessAll()
+		q1.Start()
+		err = b1.Wait()
 		require.NoError(t, err)
 
 		require.Equal(t, len(items), len(processed))
@@ -44,19 +47,22 @@ func TestQueue(t *testing.T) {
 		counts := make(map[int]int, n)
 		var mu2 sync.Mutex
 
-		q2, err := queue.New(conc)
+		q2, err := queue.New[int](conc)
 		require.NoError(t, err)
+		defer q2.Close()
+		b2 := q2.Batch()
 		for i := 0; i < n; i++ {
 			i := i
-			q2.Enqueue(func() error {
+			b2.Submit(func() (int, error) {
 				mu2.Lock()
 				counts[i]++
 				mu2.Unlock()
-				return nil
-			})
+				return 0, nil
+			}, queue.PriorityNormal)
 		}
 
-		err = q2.ProcessAll()
+		q2.Start()
+		err = b2.Wait()
 		require.NoError(t, err)
 
 		require.Equal(t, n, len(counts))
@@ -70,20 +76,23 @@ func TestQueue(t *testing.T) {
 		var processedCount int32
 		failOn := 30
 
-		q3, err := queue.New(1)
+		q3, err := queue.New[int](1)
 		require.NoError(t, err)
+		defer q3.Close()
+		b3 := q3.Batch()
 		for _, it := range errorItems {
 			it := it
-			q3.Enqueue(func() error {
+			b3.Submit(func() (int, error) {
 				atomic.AddInt32(&processedCount, 1)
 				if it == failOn {
-					return errors.New("boom")
+					return 0, errors.New("boom")
 				}
-				return nil
-			})
+				return 0, nil
+			}, queue.PriorityNormal)
 		}
 
-		err = q3.ProcessAll()
+		q3.Start()
+		err = b3.Wait()
 		require.Error(t, err)
 
 		assert.Equal(t, int32(3), processedCount)
@@ -96,10 +105,12 @@ func TestQueue(t *testing.T) {
 		var maxActive int32
 		var mu3 sync.Mutex
 
-		q4, err := queue.New(concLimit)
+		q4, err := queue.New[int](concLimit)
 		require.NoError(t, err)
+		defer q4.Close()
+		b4 := q4.Batch()
 		for i := 0; i < nParallel; i++ {
-			q4.Enqueue(func() error {
+			b4.Submit(func() (int, error) {
 				cur := atomic.AddInt32(&active, 1)
 				mu3.Lock()
 				if cur > maxActive {
@@ -108,20 +119,24 @@ func TestQueue(t *testing.T) {
 				mu3.Unlock()
 				time.Sleep(10 * time.Millisecond)
 				atomic.AddInt32(&active, -1)
-				return nil
-			})
+				return 0, nil
+			}, queue.PriorityNormal)
 		}
 
-		err = q4.ProcessAll()
+		q4.Start()
+		err = b4.Wait()
 		require.NoError(t, err)
 
 		assert.LessOrEqual(t, int(maxActive), concLimit)
 	})
 
 	t.Run("empty queue returns nil error", func(t *testing.T) {
-		q5, err := queue.New(3)
+		q5, err := queue.New[int](3)
 		require.NoError(t, err)
-		err = q5.ProcessAll()
+		defer q5.Close()
+		b5 := q5.Batch()
+		q5.Start()
+		err = b5.Wait()
 		assert.NoError(t, err)
 	})
 
@@ -131,24 +146,27 @@ func TestQueue(t *testing.T) {
 		conc := 6
 		counts := make(map[int]int, itemCount)
 		var mu sync.Mutex
-		q, err := queue.New(conc)
+		q, err := queue.New[int](conc)
 		require.NoError(t, err)
+		defer q.Close()
+		b := q.Batch()
 
 		for i := 0; i < itemCount; i++ {
 			i := i
-			q.Enqueue(func() error {
+			b.Submit(func() (int, error) {
 				mu.Lock()
 				counts[i]++
 				mu.Unlock()
 				if i == failOn {
-					return errors.New("boom")
+					return 0, errors.New("boom")
 				}
 				time.Sleep(2 * time.Millisecond)
-				return nil
-			})
+				return 0, nil
+			}, queue.PriorityNormal)
 		}
 
-		err = q.ProcessAll()
+		q.Start()
+		err = b.Wait()
 		require.Error(t, err)
 
 		require.Equal(t, 1, counts[failOn])
@@ -165,8 +183,275 @@ func TestQueue(t *testing.T) {
 		assert.True(t, unprocessedExists, "expected some items to remain unprocessed after error")
 	})
 
+	t.Run("stop finishes running tasks without starting new ones", func(t *testing.T) {
+		var processedCount int32
+		q, err := queue.New[int](2)
+		require.NoError(t, err)
+		defer q.Close()
+		b := q.Batch()
+
+		for i := 0; i < 20; i++ {
+			b.Submit(func() (int, error) {
+				if atomic.AddInt32(&processedCount, 1) == 2 {
+					b.Stop()
+				}
+				time.Sleep(5 * time.Millisecond)
+				return 0, nil
+			}, queue.PriorityNormal)
+		}
+
+		q.Start()
+		err = b.Wait()
+		require.ErrorIs(t, err, queue.ErrStopped)
+		assert.LessOrEqual(t, atomic.LoadInt32(&processedCount), int32(3))
+	})
+
+	t.Run("stop after all tasks are processed returns nil error", func(t *testing.T) {
+		q, err := queue.New[int](2)
+		require.NoError(t, err)
+		defer q.Close()
+		b := q.Batch()
+		b.Submit(func() (int, error) {
+			b.Stop()
+			return 0, nil
+		}, queue.PriorityNormal)
+
+		q.Start()
+		err = b.Wait()
+		assert.NoError(t, err)
+	})
+
+	t.Run("pause holds new tasks until resume", func(t *testing.T) {
+		var processedCount int32
+		q, err := queue.New[int](2)
+		require.NoError(t, err)
+		defer q.Close()
+		b := q.Batch()
+
+		b.Submit(func() (int, error) {
+			b.Pause()
+			atomic.AddInt32(&processedCount, 1)
+			go func() {
+				time.Sleep(20 * time.Millisecond)
+				assert.Equal(t, int32(1), atomic.LoadInt32(&processedCount))
+				b.Resume()
+			}()
+			return 0, nil
+		}, queue.PriorityNormal)
+		for i := 0; i < 5; i++ {
+			b.Submit(func() (int, error) {
+				atomic.AddInt32(&processedCount, 1)
+				return 0, nil
+			}, queue.PriorityNormal)
+		}
+
+		q.Start()
+		err = b.Wait()
+		require.NoError(t, err)
+		assert.Equal(t, int32(6), atomic.LoadInt32(&processedCount))
+	})
+
+	t.Run("stop releases paused workers", func(t *testing.T) {
+		var processedCount int32
+		q, err := queue.New[int](2)
+		require.NoError(t, err)
+		defer q.Close()
+		b := q.Batch()
+
+		for i := 0; i < 5; i++ {
+			b.Submit(func() (int, error) {
+				atomic.AddInt32(&processedCount, 1)
+				return 0, nil
+			}, queue.PriorityNormal)
+		}
+		b.Pause()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			b.Stop()
+		}()
+
+		q.Start()
+		err = b.Wait()
+		assert.ErrorIs(t, err, queue.ErrStopped)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&processedCount))
+	})
+
+	t.Run("failing batch does not affect other batches", func(t *testing.T) {
+		q, err := queue.New[int](2)
+		require.NoError(t, err)
+		defer q.Close()
+		q.Start()
+
+		failing := q.Batch()
+		failing.Submit(func() (int, error) {
+			return 0, errors.New("boom")
+		}, queue.PriorityNormal)
+		require.Error(t, failing.Wait())
+
+		var processedCount int32
+		b := q.Batch()
+		for i := 0; i < 10; i++ {
+			b.Submit(func() (int, error) {
+				atomic.AddInt32(&processedCount, 1)
+				return 0, nil
+			}, queue.PriorityNormal)
+		}
+		require.NoError(t, b.Wait())
+		assert.Equal(t, int32(10), atomic.LoadInt32(&processedCount))
+	})
+
+	t.Run("paused batch does not hold other batches", func(t *testing.T) {
+		q, err := queue.New[int](1)
+		require.NoError(t, err)
+		defer q.Close()
+
+		paused := q.Batch()
+		paused.Pause()
+		paused.Submit(func() (int, error) { return 0, nil }, queue.PriorityNormal)
+
+		var processed atomic.Bool
+		b := q.Batch()
+		b.Submit(func() (int, error) {
+			processed.Store(true)
+			return 0, nil
+		}, queue.PriorityNormal)
+		q.Start()
+
+		require.NoError(t, b.Wait())
+		assert.True(t, processed.Load())
+		paused.Stop()
+		assert.ErrorIs(t, paused.Wait(), queue.ErrStopped)
+	})
+
+	t.Run("paused queue holds all batches until resumed", func(t *testing.T) {
+		var processedCount int32
+		q, err := queue.New[int](2)
+		require.NoError(t, err)
+		defer q.Close()
+
+		q.Pause()
+		q.Pause()
+		var batches []*queue.Batch[int]
+		for i := 0; i < 2; i++ {
+			b := q.Batch()
+			b.Submit(func() (int, error) {
+				atomic.AddInt32(&processedCount, 1)
+				return 0, nil
+			}, queue.PriorityNormal)
+			batches = append(batches, b)
+		}
+		q.Start()
+
+		q.Resume()
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&processedCount))
+
+		q.Resume()
+		for _, b := range batches {
+			require.NoError(t, b.Wait())
+		}
+		assert.Equal(t, int32(2), atomic.LoadInt32(&processedCount))
+	})
+
+	t.Run("close processes submitted tasks", func(t *testing.T) {
+		var processedCount int32
+		q, err := queue.New[int](2)
+		require.NoError(t, err)
+		b := q.Batch()
+		for i := 0; i < 10; i++ {
+			b.Submit(func() (int, error) {
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&processedCount, 1)
+				return 0, nil
+			}, queue.PriorityNormal)
+		}
+		q.Start()
+		q.Close()
+
+		assert.Equal(t, int32(10), atomic.LoadInt32(&processedCount))
+		assert.NoError(t, b.Wait())
+	})
+
+	t.Run("submit after close is rejected", func(t *testing.T) {
+		q, err := queue.New[int](1)
+		require.NoError(t, err)
+		q.Start()
+		q.Close()
+
+		b := q.Batch()
+		b.Submit(func() (int, error) { return 0, nil }, queue.PriorityNormal)
+		assert.ErrorIs(t, b.Wait(), queue.ErrClosed)
+	})
+
+	t.Run("higher priorities are processed first", func(t *testing.T) {
+		q, err := queue.New[int](1)
+		require.NoError(t, err)
+		defer q.Close()
+		b := q.Batch()
+
+		priorities := []queue.Priority{queue.PriorityNormal, queue.PriorityLow, queue.PriorityHigh, queue.PriorityNormal, queue.PriorityHigh}
+		for i, priority := range priorities {
+			b.Submit(func() (int, error) { return i, nil }, priority)
+		}
+		b.Close()
+		q.Start()
+
+		var order []int
+		for result := range b.Results() {
+			require.NoError(t, result.Err)
+			order = append(order, result.Value)
+		}
+		assert.Equal(t, []int{2, 4, 0, 3, 1}, order)
+	})
+
+	t.Run("results stream every processed task", func(t *testing.T) {
+		q, err := queue.New[int](4)
+		require.NoError(t, err)
+		defer q.Close()
+		q.Start()
+		b := q.Batch()
+
+		sum := 0
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for result := range b.Results() {
+				sum += result.Value
+			}
+		}()
+		for i := 1; i <= 10; i++ {
+			b.Submit(func() (int, error) { return i, nil }, queue.PriorityNormal)
+		}
+		b.Close()
+
+		<-done
+		assert.Equal(t, 55, sum)
+		assert.NoError(t, b.Wait())
+	})
+
+	t.Run("results are closed when the batch is stopped", func(t *testing.T) {
+		q, err := queue.New[int](1)
+		require.NoError(t, err)
+		defer q.Close()
+		b := q.Batch()
+		for i := 0; i < 5; i++ {
+			b.Submit(func() (int, error) {
+				b.Stop()
+				return i, nil
+			}, queue.PriorityNormal)
+		}
+		q.Start()
+
+		var results []queue.Result[int]
+		for result := range b.Results() {
+			results = append(results, result)
+		}
+		assert.Len(t, results, 1)
+		assert.ErrorIs(t, b.Wait(), queue.ErrStopped)
+	})
+
 	t.Run("invalid concurrency returns error", func(t *testing.T) {
-		q, err := queue.New(0)
+		q, err := queue.New[int](0)
 		require.Error(t, err)
 		assert.Nil(t, q)
 	})
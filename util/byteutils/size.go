@@ -0,0 +1,62 @@
+package byteutils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+var sizePattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([a-z]*)$`)
+
+// ParseSize treats units with "i" as binary and others as decimal.
+func ParseSize(value string) (int64, error) {
+	match := sizePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if match == nil {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+
+	unit, ok := sizeUnits[match[2]]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit %q", match[2])
+	}
+
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", value, err)
+	}
+
+	size := number * unit
+	if size > math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is too large", value)
+	}
+	return int64(size), nil
+}
+
+func ParseRate(value string) (int64, error) {
+	trimmed := strings.TrimSpace(value)
+	trimmed = strings.TrimSuffix(strings.TrimSuffix(trimmed, "/s"), "/S")
+	rate, err := ParseSize(trimmed)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q", value)
+	}
+	return rate, nil
+}
@@ -0,0 +1,79 @@
+package byteutils_test
+
+import (
+	"testing"
+
+	"github.com/MatthiasHarzer/patreon-crawler/util/byteutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	t.Run("parses sizes", func(t *testing.T) {
+		tests := []struct {
+			value    string
+			expected int64
+		}{
+			{value: "0", expected: 0},
+			{value: "512", expected: 512},
+			{value: "512B", expected: 512},
+			{value: "100KB", expected: 100_000},
+			{value: "100k", expected: 100_000},
+			{value: "1.5MB", expected: 1_500_000},
+			{value: "2 GB", expected: 2_000_000_000},
+			{value: "1KiB", expected: 1024},
+			{value: "2mib", expected: 2 * 1024 * 1024},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.value, func(t *testing.T) {
+				size, err := byteutils.ParseSize(tt.value)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, size)
+			})
+		}
+	})
+
+	t.Run("fails for invalid sizes", func(t *testing.T) {
+		values := []string{"", "MB", "-1MB", "1XB", "1.2.3MB"}
+
+		for _, value := range values {
+			t.Run(value, func(t *testing.T) {
+				_, err := byteutils.ParseSize(value)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestParseRate(t *testing.T) {
+	t.Run("parses rates", func(t *testing.T) {
+		tests := []struct {
+			value    string
+			expected int64
+		}{
+			{value: "0", expected: 0},
+			{value: "5MB/s", expected: 5_000_000},
+			{value: "500KiB/s", expected: 500 * 1024},
+			{value: "1MB", expected: 1_000_000},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.value, func(t *testing.T) {
+				rate, err := byteutils.ParseRate(tt.value)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, rate)
+			})
+		}
+	})
+
+	t.Run("fails for invalid rates", func(t *testing.T) {
+		for _, value := range []string{"", "fast", "5MB/h"} {
+			t.Run(value, func(t *testing.T) {
+				_, err := byteutils.ParseRate(value)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package cookieutils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Cookie struct {
+	Domain  string
+	Name    string
+	Value   string
+	Expires time.Time
+}
+
+// jsonCookie is the format written by EditThisCookie and similar extensions.
+type jsonCookie struct {
+	Domain         string  `json:"domain"`
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	ExpirationDate float64 `json:"expirationDate"`
+	Session        bool    `json:"session"`
+}
+
+const httpOnlyPrefix = "#HttpOnly_"
+
+func ParseNetscape(data []byte) ([]Cookie, error) {
+	var cookies []Cookie
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		line = strings.TrimPrefix(line, httpOnlyPrefix)
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab-separated fields, got %d", lineNumber, len(fields))
+		}
+		expiresUnix, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry %q", lineNumber, fields[4])
+		}
+
+		var expires time.Time
+		if expiresUnix > 0 {
+			expires = time.Unix(expiresUnix, 0)
+		}
+		cookies = append(cookies, Cookie{
+			Domain:  fields[0],
+			Name:    fields[5],
+			Value:   fields[6],
+			Expires: expires,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+func ParseJSON(data []byte) ([]Cookie, error) {
+	var jsonCookies []jsonCookie
+	err := json.Unmarshal(data, &jsonCookies)
+	if err != nil {
+		return nil, err
+	}
+
+	cookies := make([]Cookie, 0, len(jsonCookies))
+	for _, jsonCookie := range jsonCookies {
+		var expires time.Time
+		if !jsonCookie.Session && jsonCookie.ExpirationDate > 0 {
+			expires = time.Unix(int64(jsonCookie.ExpirationDate), 0)
+		}
+		cookies = append(cookies, Cookie{
+			Domain:  jsonCookie.Domain,
+			Name:    jsonCookie.Name,
+			Value:   jsonCookie.Value,
+			Expires: expires,
+		})
+	}
+	return cookies, nil
+}
+
+func Parse(data []byte) ([]Cookie, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		return ParseJSON(trimmed)
+	}
+	return ParseNetscape(data)
+}
+
+func (c Cookie) MatchesDomain(domain string) bool {
+	cookieDomain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	domain = strings.ToLower(domain)
+	return cookieDomain == domain || strings.HasSuffix(cookieDomain, "."+domain)
+}
+
+// Header skips cookies expired at the given time.
+func Header(cookies []Cookie, domain string, now time.Time) string {
+	var pairs []string
+	for _, cookie := range cookies {
+		if !cookie.MatchesDomain(domain) {
+			continue
+		}
+		if !cookie.Expires.IsZero() && !cookie.Expires.After(now) {
+			continue
+		}
+		pairs = append(pairs, cookie.Name+"="+cookie.Value)
+	}
+	return strings.Join(pairs, "; ")
+}
+
+func ReadHeader(path string, domain string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	cookies, err := Parse(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse cookies file %s: %w", path, err)
+	}
+	header := Header(cookies, domain, time.Now())
+	if header == "" {
+		return "", fmt.Errorf("no unexpired %s cookies found in %s", domain, path)
+	}
+	return header, nil
+}
@@ -0,0 +1,92 @@
+package cookieutils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MatthiasHarzer/patreon-crawler/util/cookieutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+const netscapeCookies = "# Netscape HTTP Cookie File\n" +
+	"\n" +
+	"#HttpOnly_.patreon.com\tTRUE\t/\tTRUE\t1893456000\tsession_id\tabc123\n" +
+	"www.patreon.com\tFALSE\t/\tFALSE\t0\tpatreon_device_id\tdevice\n" +
+	".patreon.com\tTRUE\t/\tFALSE\t1600000000\texpired\told\n" +
+	".example.com\tTRUE\t/\tFALSE\t1893456000\tother\tvalue\n"
+
+const jsonCookies = `[
+	{"domain": ".patreon.com", "expirationDate": 1893456000.5, "hostOnly": false, "httpOnly": true, "name": "session_id", "path": "/", "secure": true, "session": false, "value": "abc123"},
+	{"domain": "www.patreon.com", "hostOnly": true, "name": "patreon_device_id", "path": "/", "session": true, "value": "device"},
+	{"domain": ".patreon.com", "expirationDate": 1600000000, "name": "expired", "session": false, "value": "old"},
+	{"domain": "notpatreon.com", "expirationDate": 1893456000, "name": "other", "session": false, "value": "value"}
+]`
+
+func TestParse(t *testing.T) {
+	t.Run("parses Netscape cookies.txt files", func(t *testing.T) {
+		cookies, err := cookieutils.Parse([]byte(netscapeCookies))
+		require.NoError(t, err)
+		require.Len(t, cookies, 4)
+		assert.Equal(t, cookieutils.Cookie{
+			Domain:  ".patreon.com",
+			Name:    "session_id",
+			Value:   "abc123",
+			Expires: time.Unix(1893456000, 0),
+		}, cookies[0])
+		assert.True(t, cookies[1].Expires.IsZero())
+	})
+
+	t.Run("parses JSON exports", func(t *testing.T) {
+		cookies, err := cookieutils.Parse([]byte(jsonCookies))
+		require.NoError(t, err)
+		require.Len(t, cookies, 4)
+		assert.Equal(t, cookieutils.Cookie{
+			Domain:  ".patreon.com",
+			Name:    "session_id",
+			Value:   "abc123",
+			Expires: time.Unix(1893456000, 0),
+		}, cookies[0])
+		assert.True(t, cookies[1].Expires.IsZero())
+	})
+
+	t.Run("rejects malformed lines", func(t *testing.T) {
+		_, err := cookieutils.Parse([]byte(".patreon.com\tTRUE\t/\n"))
+		assert.ErrorContains(t, err, "line 1")
+	})
+}
+
+func TestHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "netscape", data: netscapeCookies},
+		{name: "json", data: jsonCookies},
+	}
+
+	for _, tt := range tests {
+		t.Run("keeps unexpired cookies of the domain from "+tt.name, func(t *testing.T) {
+			cookies, err := cookieutils.Parse([]byte(tt.data))
+			require.NoError(t, err)
+
+			header := cookieutils.Header(cookies, "patreon.com", now)
+			assert.Equal(t, "session_id=abc123; patreon_device_id=device", header)
+		})
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	t.Run("returns an error if no cookies match", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cookies.txt")
+		require.NoError(t, os.WriteFile(path, []byte("# Netscape HTTP Cookie File\n"), 0600))
+
+		_, err := cookieutils.ReadHeader(path, "patreon.com")
+		assert.ErrorContains(t, err, "no unexpired patreon.com cookies")
+	})
+}
@@ -0,0 +1,58 @@
+package logutils
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type Level string
+
+const (
+	LevelDebug Level = "debug"
+	LevelInfo  Level = "info"
+	LevelWarn  Level = "warn"
+	LevelError Level = "error"
+)
+
+func parseLevel(level Level) (slog.Level, error) {
+	switch Level(strings.ToLower(string(level))) {
+	case LevelDebug:
+		return slog.LevelDebug, nil
+	case LevelInfo:
+		return slog.LevelInfo, nil
+	case LevelWarn:
+		return slog.LevelWarn, nil
+	case LevelError:
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level %q. Must be one of: debug, info, warn, error", level)
+	}
+}
+
+// Setup writes to stderr without a log file, so records don't interleave with
+// the output on stdout.
+func Setup(level Level, logFile string) (func() error, error) {
+	slogLevel, err := parseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	var writer io.Writer = os.Stderr
+	closeWriter := func() error { return nil }
+	if logFile != "" {
+		file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		writer = file
+		closeWriter = file.Close
+	}
+
+	handler := slog.NewTextHandler(writer, &slog.HandlerOptions{Level: slogLevel})
+	slog.SetDefault(slog.New(handler))
+
+	return closeWriter, nil
+}
@@ -0,0 +1,49 @@
+package logutils
+
+import (
+	"net/url"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveQueryKeys match the signed tokens on patreon media URLs.
+var sensitiveQueryKeys = []string{"token", "sig", "hash", "key", "auth", "session", "policy", "expires"}
+
+func isSensitiveQueryKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitiveKey := range sensitiveQueryKeys {
+		if strings.Contains(key, sensitiveKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func RedactURL(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return redacted
+	}
+
+	if parsedURL.User != nil {
+		parsedURL.User = url.User(redacted)
+	}
+
+	query := parsedURL.Query()
+	changed := false
+	for key, values := range query {
+		if !isSensitiveQueryKey(key) {
+			continue
+		}
+		for i := range values {
+			values[i] = redacted
+		}
+		changed = true
+	}
+	if changed {
+		parsedURL.RawQuery = query.Encode()
+	}
+
+	return parsedURL.String()
+}
@@ -0,0 +1,39 @@
+package netutils
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ParseProxyURL returns nil for an empty value.
+func ParseProxyURL(value string) (*url.URL, error) {
+	if value == "" {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	proxyURL.Scheme = strings.ToLower(proxyURL.Scheme)
+	if !slices.Contains(proxySchemes, proxyURL.Scheme) {
+		return nil, fmt.Errorf("invalid proxy URL %q. The scheme must be one of: %s", proxyURL.Redacted(), strings.Join(proxySchemes, ", "))
+	}
+	if proxyURL.Hostname() == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", proxyURL.Redacted())
+	}
+	return proxyURL, nil
+}
+
+// ProxyFunc falls back to the proxy configured in the environment if proxy is
+// nil.
+func ProxyFunc(proxy *url.URL) func(*http.Request) (*url.URL, error) {
+	if proxy == nil {
+		return http.ProxyFromEnvironment
+	}
+	return http.ProxyURL(proxy)
+}